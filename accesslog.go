@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// accessLogResponseWriter keeps the status code of the response (access logging, rejected scrapes)
+	accessLogResponseWriter struct {
+		http.ResponseWriter
+		statusCode int
+	}
+)
+
+func (w *accessLogResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// accessLogHandler logs each request (uses the logrus formatter, json if log.json is set)
+func accessLogHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		responseWriter := &accessLogResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		handler.ServeHTTP(responseWriter, r)
+
+		log.WithFields(log.Fields{
+			"type":       "access",
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"status":     responseWriter.statusCode,
+			"duration":   time.Since(startTime).Seconds(),
+			"remoteAddr": r.RemoteAddr,
+			"userAgent":  r.UserAgent(),
+		}).Info("http request")
+	})
+}
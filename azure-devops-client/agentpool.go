@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -110,6 +111,40 @@ type AgentPoolAgent struct {
 	Version           string
 	CreatedOn         time.Time
 	AssignedRequest   JobRequest
+
+	SystemCapabilities map[string]string
+	UserCapabilities   map[string]string
+}
+
+// Capability returns the value of the (system or user) capability
+func (a *AgentPoolAgent) Capability(name string) (string, bool) {
+	for _, capabilities := range []map[string]string{a.UserCapabilities, a.SystemCapabilities} {
+		for capabilityName, capabilityValue := range capabilities {
+			if strings.EqualFold(capabilityName, name) {
+				return capabilityValue, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// SatisfiesDemands checks if the agent satisfies all demands (form: 'name' or 'name -equals value')
+func (a *AgentPoolAgent) SatisfiesDemands(demands []string) bool {
+	for _, demand := range demands {
+		parts := strings.SplitN(demand, " -equals ", 2)
+
+		value, exists := a.Capability(strings.TrimSpace(parts[0]))
+		if !exists {
+			return false
+		}
+
+		if len(parts) == 2 && !strings.EqualFold(value, strings.TrimSpace(parts[1])) {
+			return false
+		}
+	}
+
+	return true
 }
 
 type JobRequest struct {
@@ -155,13 +190,14 @@ func (c *AzureDevopsClient) ListAgentPools() (list AgentPoolList, error error) {
 	return
 }
 
-func (c *AzureDevopsClient) ListAgentPoolAgents(agentPoolId int64) (list AgentPoolAgentList, error error) {
+func (c *AzureDevopsClient) ListAgentPoolAgents(agentPoolId int64, includeCapabilities bool) (list AgentPoolAgentList, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
 
 	url := fmt.Sprintf(
-		"/_apis/distributedtask/pools/%v/agents?includeCapabilities=false&includeAssignedRequest=true",
+		"/_apis/distributedtask/pools/%v/agents?includeCapabilities=%v&includeAssignedRequest=true",
 		fmt.Sprintf("%d", agentPoolId),
+		includeCapabilities,
 	)
 	response, err := c.rest().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
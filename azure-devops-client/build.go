@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,50 @@ type BuildDefinition struct {
 	QueueStatus     string
 	BuildNameFormat string
 	Links           Links `json:"_links"`
+
+	// revision date (last modification of the definition)
+	CreatedDate time.Time   `json:"createdDate"`
+	AuthoredBy  IdentifyRef `json:"authoredBy"`
+
+	Triggers []BuildDefinitionTrigger `json:"triggers"`
+
+	// agent capabilities required by the definition
+	Demands []BuildDefinitionDemand `json:"demands"`
+}
+
+type BuildDefinitionDemand struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UnmarshalJSON supports demands as object ({"name": "java"}) and as string ("Agent.OS -equals Linux")
+func (d *BuildDefinitionDemand) UnmarshalJSON(data []byte) error {
+	var demand string
+	if err := json.Unmarshal(data, &demand); err == nil {
+		parts := strings.SplitN(demand, " -equals ", 2)
+		d.Name = strings.TrimSpace(parts[0])
+		if len(parts) == 2 {
+			d.Value = strings.TrimSpace(parts[1])
+		}
+		return nil
+	}
+
+	type demandObject BuildDefinitionDemand
+	return json.Unmarshal(data, (*demandObject)(d))
+}
+
+type BuildDefinitionTrigger struct {
+	TriggerType string `json:"triggerType"`
+}
+
+func (d *BuildDefinition) HasTrigger(triggerType string) bool {
+	for _, trigger := range d.Triggers {
+		if strings.EqualFold(trigger.TriggerType, triggerType) {
+			return true
+		}
+	}
+
+	return false
 }
 
 type BuildList struct {
@@ -27,6 +72,27 @@ type BuildList struct {
 	List  []Build `json:"value"`
 }
 
+type BuildArtifactList struct {
+	Count int             `json:"count"`
+	List  []BuildArtifact `json:"value"`
+}
+
+type BuildArtifact struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type BuildTestRunList struct {
+	Count int            `json:"count"`
+	List  []BuildTestRun `json:"value"`
+}
+
+type BuildTestRun struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
 type TimelineRecordList struct {
 	List []TimelineRecord `json:"records"`
 }
@@ -72,6 +138,18 @@ type Build struct {
 	RequestedBy  IdentifyRef
 	RequestedFor IdentifyRef
 
+	Repository struct {
+		Id   string `json:"id"`
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"repository"`
+
+	// validation (eg. yaml parse) errors, build fails before any stage ran
+	ValidationResults []struct {
+		Result  string `json:"result"`
+		Message string `json:"message"`
+	} `json:"validationResults"`
+
 	Links Links `json:"_links"`
 }
 
@@ -79,22 +157,39 @@ func (b *Build) QueueDuration() time.Duration {
 	return b.StartTime.Sub(b.QueueTime)
 }
 
-func (c *AzureDevopsClient) ListBuildDefinitions(project string) (list BuildDefinitionList, error error) {
+// IsValidationFailure checks if the build failed because of validation errors (eg. yaml syntax errors)
+func (b *Build) IsValidationFailure() bool {
+	if b.Result != "failed" {
+		return false
+	}
+
+	for _, validationResult := range b.ValidationResults {
+		if strings.EqualFold(validationResult.Result, "error") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListBuildDefinitions lists the build definitions, triggers and demands are only included with allProperties
+func (c *AzureDevopsClient) ListBuildDefinitions(project string, allProperties bool) (list BuildDefinitionList, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
 
 	url := fmt.Sprintf(
-		"%v/_apis/build/definitions?api-version=%v&$top=9999",
+		"%v/_apis/build/definitions?api-version=%v&$top=9999&includeAllProperties=%v",
 		url.QueryEscape(project),
 		url.QueryEscape(c.ApiVersion),
+		allProperties,
 	)
-	response, err := c.rest().R().Get(url)
-	if err := c.checkResponse(response, err); err != nil {
+	body, err := c.getCached(CacheCategoryBuildDefinitions, c.rest(), url)
+	if err != nil {
 		error = err
 		return
 	}
 
-	err = json.Unmarshal(response.Body(), &list)
+	err = json.Unmarshal(body, &list)
 	if err != nil {
 		error = err
 		return
@@ -153,6 +248,31 @@ func (c *AzureDevopsClient) ListLatestBuilds(project string) (list BuildList, er
 	return
 }
 
+func (c *AzureDevopsClient) ListLatestSuccessfulBuilds(project string) (list BuildList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/build/builds?api-version=%v&maxBuildsPerDefinition=%s&resultFilter=succeeded&deletedFilter=excludeDeleted",
+		url.QueryEscape(project),
+		url.QueryEscape(c.ApiVersion),
+		url.QueryEscape("1"),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
 func (c *AzureDevopsClient) ListBuildHistory(project string, minTime time.Time) (list BuildList, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
@@ -164,7 +284,7 @@ func (c *AzureDevopsClient) ListBuildHistory(project string, minTime time.Time)
 		url.QueryEscape(minTime.Format(time.RFC3339)),
 		url.QueryEscape(int64ToString(c.LimitBuildsPerProject)),
 	)
-	response, err := c.rest().R().Get(url)
+	response, err := c.restQuery().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
 		error = err
 		return
@@ -190,7 +310,32 @@ func (c *AzureDevopsClient) ListBuildHistoryWithStatus(project string, minTime t
 		url.QueryEscape(minTime.Format(time.RFC3339)),
 		url.QueryEscape(statusFilter),
 	)
-	response, err := c.rest().R().Get(url)
+	response, err := c.restQuery().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+// ListRunningBuilds returns all builds in progress (not limited by finish time like the build history)
+func (c *AzureDevopsClient) ListRunningBuilds(project string) (list BuildList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/build/builds?api-version=%v&statusFilter=inProgress&queryOrder=queueTimeDescending",
+		url.QueryEscape(project),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.restQuery().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
 		error = err
 		return
@@ -214,6 +359,81 @@ func (c *AzureDevopsClient) ListBuildTimeline(project string, buildID string) (l
 		url.QueryEscape(project),
 		url.QueryEscape(buildID),
 	)
+	response, err := c.restQuery().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) GetBuild(project string, buildID string) (build Build, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/build/builds/%v?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(buildID),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &build)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) ListBuildArtifacts(project string, buildID string) (list BuildArtifactList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/build/builds/%v/artifacts?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(buildID),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) ListBuildTestRuns(project string, buildUri string) (list BuildTestRunList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/test/runs?buildUri=%v&api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(buildUri),
+		url.QueryEscape(c.ApiVersion),
+	)
 	response, err := c.rest().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
 		error = err
@@ -0,0 +1,103 @@
+package AzureDevopsClient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+	cache "github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// cache categories for slow changing data
+	CacheCategoryProjects           = "projects"
+	CacheCategoryRepositories       = "repositories"
+	CacheCategoryBuildDefinitions   = "builddefinitions"
+	CacheCategoryReleaseDefinitions = "releasedefinitions"
+)
+
+var (
+	CacheCategoryList = []string{
+		CacheCategoryProjects,
+		CacheCategoryRepositories,
+		CacheCategoryBuildDefinitions,
+		CacheCategoryReleaseDefinitions,
+	}
+)
+
+type responseCache struct {
+	lock  sync.RWMutex
+	ttl   map[string]time.Duration
+	cache *cache.Cache
+
+	prometheus struct {
+		requests *prometheus.CounterVec
+	}
+}
+
+func (c *AzureDevopsClient) initResponseCache() {
+	c.responseCache.ttl = map[string]time.Duration{}
+	c.responseCache.cache = cache.New(cache.NoExpiration, 1*time.Minute)
+
+	c.responseCache.prometheus.requests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "azure_devops_api_cache_requests",
+			Help: "AzureDevOps API response cache requests (hits and misses)",
+		},
+		[]string{"category", "result"},
+	)
+	prometheus.MustRegister(c.responseCache.prometheus.requests)
+}
+
+// SetCacheTTL enables caching of GET responses of the category for the ttl (0 = disabled)
+func (c *AzureDevopsClient) SetCacheTTL(category string, ttl time.Duration) error {
+	valid := false
+	for _, val := range CacheCategoryList {
+		if val == category {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return fmt.Errorf("cache category '%v' is unknown; should be one of %v", category, CacheCategoryList)
+	}
+
+	c.responseCache.lock.Lock()
+	defer c.responseCache.lock.Unlock()
+	c.responseCache.ttl[category] = ttl
+
+	return nil
+}
+
+// getCached fetches the url and returns the response body, responses are cached if a ttl is set for the category
+func (c *AzureDevopsClient) getCached(category string, restClient *resty.Client, url string) (body []byte, error error) {
+	c.responseCache.lock.RLock()
+	ttl := c.responseCache.ttl[category]
+	c.responseCache.lock.RUnlock()
+
+	cacheKey := fmt.Sprintf("%v:%v%v", category, restClient.BaseURL, url)
+
+	if ttl.Seconds() > 0 {
+		if val, ok := c.responseCache.cache.Get(cacheKey); ok {
+			c.responseCache.prometheus.requests.With(prometheus.Labels{"category": category, "result": "hit"}).Inc()
+			return val.([]byte), nil
+		}
+		c.responseCache.prometheus.requests.With(prometheus.Labels{"category": category, "result": "miss"}).Inc()
+	}
+
+	response, err := restClient.R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+	body = response.Body()
+
+	if ttl.Seconds() > 0 {
+		c.responseCache.cache.Set(cacheKey, body, ttl)
+	}
+
+	return
+}
@@ -0,0 +1,142 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// dashboard api is only available as preview
+	DashboardApiVersion = "7.1-preview.3"
+)
+
+type DashboardList struct {
+	Count int         `json:"count"`
+	List  []Dashboard `json:"value"`
+}
+
+type Dashboard struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	OwnerId        string `json:"ownerId"`
+	GroupId        string `json:"groupId"`
+	DashboardScope string `json:"dashboardScope"`
+
+	Widgets []DashboardWidget `json:"widgets"`
+}
+
+type DashboardWidget struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+
+	ContributionId string `json:"contributionId"`
+
+	// widget specific settings (json encoded)
+	Settings string `json:"settings"`
+}
+
+// QueryIdList returns the ids of work item queries referenced by the widget settings
+// (eg. query tile, query results and work item chart widgets)
+func (w *DashboardWidget) QueryIdList() (list []string) {
+	if w.Settings == "" {
+		return
+	}
+
+	var settings interface{}
+	if err := json.Unmarshal([]byte(w.Settings), &settings); err != nil {
+		return
+	}
+
+	queryIdExists := map[string]bool{}
+	collectDashboardWidgetQueryIds(settings, func(queryId string) {
+		queryId = strings.ToLower(queryId)
+		if !queryIdExists[queryId] {
+			queryIdExists[queryId] = true
+			list = append(list, queryId)
+		}
+	})
+
+	return
+}
+
+func collectDashboardWidgetQueryIds(val interface{}, callback func(queryId string)) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if queryId, ok := child.(string); ok && strings.EqualFold(key, "queryId") {
+				if queryId != "" {
+					callback(queryId)
+				}
+				continue
+			}
+
+			collectDashboardWidgetQueryIds(child, callback)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectDashboardWidgetQueryIds(child, callback)
+		}
+	}
+}
+
+// dashboardPath returns the route prefix of project (team is empty) or team dashboards
+func dashboardPath(project string, team string) string {
+	if team == "" {
+		return url.QueryEscape(project)
+	}
+
+	return fmt.Sprintf("%v/%v", url.QueryEscape(project), url.PathEscape(team))
+}
+
+// ListDashboards lists the project dashboards (team is empty) or the dashboards of the team
+func (c *AzureDevopsClient) ListDashboards(project string, team string) (list DashboardList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/dashboard/dashboards?api-version=%v",
+		dashboardPath(project, team),
+		url.QueryEscape(DashboardApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) GetDashboard(project string, team string, dashboardId string) (dashboard Dashboard, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/dashboard/dashboards/%v?api-version=%v",
+		dashboardPath(project, team),
+		url.QueryEscape(dashboardId),
+		url.QueryEscape(DashboardApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &dashboard)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
@@ -0,0 +1,80 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// extension management api is only available as preview
+	ExtensionApiVersion = "7.1-preview.1"
+)
+
+type InstalledExtensionList struct {
+	Count int                  `json:"count"`
+	List  []InstalledExtension `json:"value"`
+}
+
+type InstalledExtension struct {
+	ExtensionId   string `json:"extensionId"`
+	ExtensionName string `json:"extensionName"`
+	PublisherId   string `json:"publisherId"`
+	PublisherName string `json:"publisherName"`
+	Version       string `json:"version"`
+	Flags         string `json:"flags"`
+
+	InstallState struct {
+		Flags string `json:"flags"`
+	} `json:"installState"`
+}
+
+// IsEnabled returns false if the extension is installed but disabled
+func (e *InstalledExtension) IsEnabled() bool {
+	return !hasExtensionFlag(e.InstallState.Flags, "disabled")
+}
+
+// IsBuiltIn returns true for extensions shipped with Azure DevOps
+func (e *InstalledExtension) IsBuiltIn() bool {
+	return hasExtensionFlag(e.Flags, "builtIn") || hasExtensionFlag(e.InstallState.Flags, "builtIn")
+}
+
+// hasExtensionFlag checks comma separated extension flags (eg. 'builtIn, trusted')
+func hasExtensionFlag(flags, flag string) bool {
+	for _, val := range strings.Split(flags, ",") {
+		if strings.EqualFold(strings.TrimSpace(val), flag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *AzureDevopsClient) ListInstalledExtensions() (list InstalledExtensionList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	// extension management is served by extmgmt.dev.azure.com (on-premises by the collection itself)
+	url := fmt.Sprintf(
+		"_apis/extensionmanagement/installedextensions?includeDisabledExtensions=true&api-version=%v",
+		url.QueryEscape(ExtensionApiVersion),
+	)
+	if c.HostUrl == nil {
+		url = fmt.Sprintf("https://extmgmt.dev.azure.com/%v/%v", *c.organization, url)
+	}
+
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
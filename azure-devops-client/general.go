@@ -1,6 +1,9 @@
 package AzureDevopsClient
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type IdentifyRef struct {
 	Id          string
@@ -41,3 +44,11 @@ type Author struct {
 	Email string
 	Date  time.Time
 }
+
+func (a *Author) Identity() string {
+	if a.Email != "" {
+		return strings.ToLower(a.Email)
+	}
+
+	return a.Name
+}
@@ -1,12 +1,16 @@
 package AzureDevopsClient
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	resty "github.com/go-resty/resty/v2"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,19 +19,31 @@ import (
 type AzureDevopsClient struct {
 	// RequestCount has to be the first words
 	// in order to be 64-aligned on 32-bit architectures.
-	RequestCount   uint64
-	RequestRetries int
+	RequestCount        uint64
+	RequestRetries      int
+	RequestTimeout      time.Duration
+	RequestTimeoutQuery time.Duration
+
+	// http status codes which are retried (additionally to connection errors)
+	retryStatusCodes map[int]bool
 
 	organization *string
 	collection   *string
 	accessToken  *string
+	authUsername string
+
+	// access tokens per project (lowercase project id), see SetProjectAccessToken
+	projectAccessTokens map[string]string
 
 	HostUrl *string
 
 	ApiVersion string
 
-	restClient     *resty.Client
-	restClientVsrm *resty.Client
+	restClient      *resty.Client
+	restClientVsrm  *resty.Client
+	restClientQuery *resty.Client
+
+	userAgent *string
 
 	semaphore   chan bool
 	concurrency int64
@@ -39,9 +55,38 @@ type AzureDevopsClient struct {
 	LimitDeploymentPerDefinition      int64
 	LimitReleaseDefinitionsPerProject int64
 	LimitReleasesPerProject           int64
+	LimitPullRequestsPerRepository    int64
+	LimitApprovalsPerProject          int64
+
+	// only list release definitions under this path (includes sub folders)
+	ReleaseDefinitionPathFilter string
+
+	// service availability (sustained 503 responses)
+	ServiceUnavailableThreshold int64
+	ServiceUnavailableBackoff   time.Duration
+	serviceUnavailable          struct {
+		lock      sync.Mutex
+		count     int64
+		lastTime  time.Time
+		available bool
+	}
+
+	// authentication state (consecutive 401 responses)
+	AuthFailureThreshold int64
+	authFailure          struct {
+		lock  sync.Mutex
+		count int64
+
+		// failures of project access tokens (per project), not counted as failure of the access token
+		projects map[string]int64
+	}
+
+	// cache for slow changing data (see SetCacheTTL)
+	responseCache responseCache
 
 	prometheus struct {
-		apiRequest *prometheus.HistogramVec
+		apiRequest       *prometheus.HistogramVec
+		serviceAvailable prometheus.Gauge
 	}
 }
 
@@ -65,6 +110,8 @@ func (c *AzureDevopsClient) Init() {
 	c.LimitDeploymentPerDefinition = 100
 	c.LimitReleaseDefinitionsPerProject = 100
 	c.LimitReleasesPerProject = 100
+	c.LimitPullRequestsPerRepository = 100
+	c.LimitApprovalsPerProject = 100
 
 	c.prometheus.apiRequest = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -76,6 +123,20 @@ func (c *AzureDevopsClient) Init() {
 	)
 
 	prometheus.MustRegister(c.prometheus.apiRequest)
+
+	c.initResponseCache()
+
+	c.serviceUnavailable.available = true
+	c.ServiceUnavailableBackoff = 5 * time.Minute
+	c.prometheus.serviceAvailable = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_service_available",
+			Help: "AzureDevOps service availability (0 after sustained 503 responses)",
+		},
+	)
+	c.prometheus.serviceAvailable.Set(1)
+
+	prometheus.MustRegister(c.prometheus.serviceAvailable)
 }
 
 func (c *AzureDevopsClient) SetConcurrency(v int64) {
@@ -93,11 +154,47 @@ func (c *AzureDevopsClient) SetRetries(v int) {
 	if c.restClientVsrm != nil {
 		c.restClientVsrm.SetRetryCount(c.RequestRetries)
 	}
+
+	if c.restClientQuery != nil {
+		c.restClientQuery.SetRetryCount(c.RequestRetries)
+	}
+}
+
+// SetRetryStatusCodes sets the http status codes which are retried (eg. 429, 503)
+func (c *AzureDevopsClient) SetRetryStatusCodes(codes []int) {
+	c.retryStatusCodes = map[int]bool{}
+	for _, code := range codes {
+		c.retryStatusCodes[code] = true
+	}
+}
+
+// SetTimeout sets the timeout for all requests (except slow requests, see SetTimeoutQuery)
+func (c *AzureDevopsClient) SetTimeout(v time.Duration) {
+	c.RequestTimeout = v
+
+	if c.restClient != nil {
+		c.restClient.SetTimeout(c.RequestTimeout)
+	}
+
+	if c.restClientVsrm != nil {
+		c.restClientVsrm.SetTimeout(c.RequestTimeout)
+	}
+}
+
+// SetTimeoutQuery sets the timeout for slow requests (WIQL, workitems, build history and timelines)
+func (c *AzureDevopsClient) SetTimeoutQuery(v time.Duration) {
+	c.RequestTimeoutQuery = v
+
+	if c.restClientQuery != nil {
+		c.restClientQuery.SetTimeout(c.RequestTimeoutQuery)
+	}
 }
 
 func (c *AzureDevopsClient) SetUserAgent(v string) {
+	c.userAgent = &v
 	c.rest().SetHeader("User-Agent", v)
 	c.restVsrm().SetHeader("User-Agent", v)
+	c.restQuery().SetHeader("User-Agent", v)
 }
 
 func (c *AzureDevopsClient) SetApiVersion(apiversion string) {
@@ -112,6 +209,19 @@ func (c *AzureDevopsClient) SetAccessToken(token string) {
 	c.accessToken = &token
 }
 
+// SetProjectAccessToken sets the access token used for requests of the project (instead of the organization access token)
+func (c *AzureDevopsClient) SetProjectAccessToken(project, token string) {
+	if c.projectAccessTokens == nil {
+		c.projectAccessTokens = map[string]string{}
+	}
+	c.projectAccessTokens[strings.ToLower(project)] = token
+}
+
+// SetAuthUsername sets the username of the basic auth header (ignored by Azure DevOps, required by some proxies)
+func (c *AzureDevopsClient) SetAuthUsername(username string) {
+	c.authUsername = username
+}
+
 func (c *AzureDevopsClient) rest() *resty.Client {
 	if c.restClient == nil {
 		c.restClient = resty.New()
@@ -121,8 +231,10 @@ func (c *AzureDevopsClient) rest() *resty.Client {
 			c.restClient.SetBaseURL(fmt.Sprintf("https://dev.azure.com/%v/", *c.organization))
 		}
 		c.restClient.SetHeader("Accept", "application/json")
-		c.restClient.SetBasicAuth("", *c.accessToken)
+		c.restClient.SetBasicAuth(c.authUsername, *c.accessToken)
 		c.restClient.SetRetryCount(c.RequestRetries)
+		c.restClient.AddRetryCondition(c.restRetryCondition)
+		c.restClient.SetTimeout(c.RequestTimeout)
 		c.restClient.OnBeforeRequest(c.restOnBeforeRequest)
 		c.restClient.OnAfterResponse(c.restOnAfterResponse)
 
@@ -140,8 +252,10 @@ func (c *AzureDevopsClient) restVsrm() *resty.Client {
 			c.restClientVsrm.SetBaseURL(fmt.Sprintf("https://vsrm.dev.azure.com/%v/", *c.organization))
 		}
 		c.restClientVsrm.SetHeader("Accept", "application/json")
-		c.restClientVsrm.SetBasicAuth("", *c.accessToken)
+		c.restClientVsrm.SetBasicAuth(c.authUsername, *c.accessToken)
 		c.restClientVsrm.SetRetryCount(c.RequestRetries)
+		c.restClientVsrm.AddRetryCondition(c.restRetryCondition)
+		c.restClientVsrm.SetTimeout(c.RequestTimeout)
 		c.restClientVsrm.OnBeforeRequest(c.restOnBeforeRequest)
 		c.restClientVsrm.OnAfterResponse(c.restOnAfterResponse)
 	}
@@ -149,6 +263,30 @@ func (c *AzureDevopsClient) restVsrm() *resty.Client {
 	return c.restClientVsrm
 }
 
+// restQuery returns the client for slow requests (WIQL, workitems, build history and timelines), same endpoint as rest() but with query timeout
+func (c *AzureDevopsClient) restQuery() *resty.Client {
+	if c.restClientQuery == nil {
+		c.restClientQuery = resty.New()
+		if c.HostUrl != nil {
+			c.restClientQuery.SetBaseURL(*c.HostUrl + "/" + *c.organization + "/")
+		} else {
+			c.restClientQuery.SetBaseURL(fmt.Sprintf("https://dev.azure.com/%v/", *c.organization))
+		}
+		c.restClientQuery.SetHeader("Accept", "application/json")
+		if c.userAgent != nil {
+			c.restClientQuery.SetHeader("User-Agent", *c.userAgent)
+		}
+		c.restClientQuery.SetBasicAuth(c.authUsername, *c.accessToken)
+		c.restClientQuery.SetRetryCount(c.RequestRetries)
+		c.restClientQuery.AddRetryCondition(c.restRetryCondition)
+		c.restClientQuery.SetTimeout(c.RequestTimeoutQuery)
+		c.restClientQuery.OnBeforeRequest(c.restOnBeforeRequest)
+		c.restClientQuery.OnAfterResponse(c.restOnAfterResponse)
+	}
+
+	return c.restClientQuery
+}
+
 func (c *AzureDevopsClient) concurrencyLock() {
 	c.semaphore <- true
 }
@@ -157,11 +295,77 @@ func (c *AzureDevopsClient) concurrencyUnlock() {
 	<-c.semaphore
 }
 
+// restRetryCondition retries connection errors and configured status codes (see SetRetryStatusCodes)
+func (c *AzureDevopsClient) restRetryCondition(response *resty.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return response != nil && c.retryStatusCodes[response.StatusCode()]
+}
+
+type requestProjectContextKey struct{}
+
+// projectRequest returns a request for the project, required for urls without project (eg. work item urls returned by WIQL)
+func (c *AzureDevopsClient) projectRequest(client *resty.Client, project string) *resty.Request {
+	return client.R().SetContext(context.WithValue(context.Background(), requestProjectContextKey{}, project))
+}
+
+// projectAccessToken returns the project of the request if a project access token is used for it
+func (c *AzureDevopsClient) projectAccessToken(request *resty.Request) (project, token string, exists bool) {
+	if len(c.projectAccessTokens) == 0 {
+		return
+	}
+
+	if val, ok := request.Context().Value(requestProjectContextKey{}).(string); ok {
+		project = strings.ToLower(val)
+	} else {
+		project = requestProject(request.URL)
+	}
+
+	token, exists = c.projectAccessTokens[project]
+	return
+}
+
 func (c *AzureDevopsClient) restOnBeforeRequest(client *resty.Client, request *resty.Request) (err error) {
 	atomic.AddUint64(&c.RequestCount, 1)
+
+	if _, token, exists := c.projectAccessToken(request); exists {
+		request.SetBasicAuth(c.authUsername, token)
+	}
 	return
 }
 
+// requestProject returns the (lowercase) project of the request url ('<project>/_apis/...' or '_apis/projects/<project>/...')
+func requestProject(requestUrl string) string {
+	requestPath := requestUrl
+	if parsedUrl, err := url.Parse(requestUrl); err == nil {
+		requestPath = parsedUrl.Path
+	}
+
+	segments := strings.Split(strings.Trim(requestPath, "/"), "/")
+	for i, segment := range segments {
+		if segment != "_apis" {
+			continue
+		}
+
+		project := ""
+		if i+2 < len(segments) && segments[i+1] == "projects" {
+			project = segments[i+2]
+		} else if i > 0 {
+			project = segments[i-1]
+		}
+
+		if val, err := url.PathUnescape(project); err == nil {
+			project = val
+		}
+
+		return strings.ToLower(project)
+	}
+
+	return ""
+}
+
 func (c *AzureDevopsClient) restOnAfterResponse(client *resty.Client, response *resty.Response) (err error) {
 	requestUrl, _ := url.Parse(response.Request.URL)
 	c.prometheus.apiRequest.With(prometheus.Labels{
@@ -170,9 +374,121 @@ func (c *AzureDevopsClient) restOnAfterResponse(client *resty.Client, response *
 		"method":       strings.ToLower(response.Request.Method),
 		"statusCode":   strconv.FormatInt(int64(response.StatusCode()), 10),
 	}).Observe(response.Time().Seconds())
+
+	c.updateServiceAvailability(response.StatusCode())
+	if project, _, exists := c.projectAccessToken(response.Request); exists {
+		c.updateProjectAuthState(project, response.StatusCode())
+	} else {
+		c.updateAuthState(response.StatusCode())
+	}
+	return
+}
+
+// authFailed checks if the response indicates an invalid token
+func authFailed(statusCode int) bool {
+	// invalid tokens are either answered with 401 or 203 (redirect to sign-in page)
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusNonAuthoritativeInfo
+}
+
+func (c *AzureDevopsClient) updateAuthState(statusCode int) {
+	c.authFailure.lock.Lock()
+	defer c.authFailure.lock.Unlock()
+
+	switch {
+	case authFailed(statusCode):
+		c.authFailure.count++
+	case statusCode < 400:
+		c.authFailure.count = 0
+	}
+}
+
+// updateProjectAuthState tracks failures of project access tokens separately, an invalid project access token
+// only affects the requests of its project
+func (c *AzureDevopsClient) updateProjectAuthState(project string, statusCode int) {
+	c.authFailure.lock.Lock()
+	defer c.authFailure.lock.Unlock()
+
+	if c.authFailure.projects == nil {
+		c.authFailure.projects = map[string]int64{}
+	}
+
+	switch {
+	case authFailed(statusCode):
+		c.authFailure.projects[project]++
+	case statusCode < 400:
+		c.authFailure.projects[project] = 0
+	}
+}
+
+// ProjectAuthFailures returns the projects whose access token reached the number of consecutive authentication failures of the threshold
+func (c *AzureDevopsClient) ProjectAuthFailures() (list []string) {
+	if c.AuthFailureThreshold <= 0 {
+		return
+	}
+
+	c.authFailure.lock.Lock()
+	defer c.authFailure.lock.Unlock()
+
+	for project, count := range c.authFailure.projects {
+		if count >= c.AuthFailureThreshold {
+			list = append(list, project)
+		}
+	}
+
 	return
 }
 
+// IsAuthValid returns false if the number of consecutive authentication failures reached the threshold
+func (c *AzureDevopsClient) IsAuthValid() bool {
+	if c.AuthFailureThreshold <= 0 {
+		return true
+	}
+
+	c.authFailure.lock.Lock()
+	defer c.authFailure.lock.Unlock()
+
+	return c.authFailure.count < c.AuthFailureThreshold
+}
+
+func (c *AzureDevopsClient) updateServiceAvailability(statusCode int) {
+	if c.ServiceUnavailableThreshold <= 0 {
+		return
+	}
+
+	c.serviceUnavailable.lock.Lock()
+	defer c.serviceUnavailable.lock.Unlock()
+
+	if statusCode == http.StatusServiceUnavailable {
+		c.serviceUnavailable.count++
+		c.serviceUnavailable.lastTime = time.Now()
+		if c.serviceUnavailable.count >= c.ServiceUnavailableThreshold {
+			c.serviceUnavailable.available = false
+		}
+	} else if statusCode < 500 {
+		c.serviceUnavailable.count = 0
+		c.serviceUnavailable.available = true
+	}
+
+	if c.serviceUnavailable.available {
+		c.prometheus.serviceAvailable.Set(1)
+	} else {
+		c.prometheus.serviceAvailable.Set(0)
+	}
+}
+
+// IsServiceAvailable returns false while AzureDevOps is considered unavailable (sustained 503 responses)
+// and the backoff since the last 503 response has not passed yet
+func (c *AzureDevopsClient) IsServiceAvailable() bool {
+	c.serviceUnavailable.lock.Lock()
+	defer c.serviceUnavailable.lock.Unlock()
+
+	if c.serviceUnavailable.available {
+		return true
+	}
+
+	return time.Since(c.serviceUnavailable.lastTime) >= c.ServiceUnavailableBackoff
+}
+
 func (c *AzureDevopsClient) GetRequestCount() float64 {
 	requestCount := atomic.LoadUint64(&c.RequestCount)
 	return float64(requestCount)
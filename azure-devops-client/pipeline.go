@@ -0,0 +1,71 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// pipelines api is not available in older api versions
+	PipelineApiVersion = "6.0-preview.1"
+)
+
+type PipelineRun struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+
+	Pipeline struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"pipeline"`
+
+	Resources struct {
+		Repositories map[string]PipelineRunRepositoryResource `json:"repositories"`
+		Pipelines    map[string]PipelineRunPipelineResource   `json:"pipelines"`
+	} `json:"resources"`
+}
+
+type PipelineRunRepositoryResource struct {
+	Repository struct {
+		Id       string `json:"id"`
+		Type     string `json:"type"`
+		FullName string `json:"fullName"`
+	} `json:"repository"`
+	RefName string `json:"refName"`
+	Version string `json:"version"`
+}
+
+type PipelineRunPipelineResource struct {
+	Pipeline struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"pipeline"`
+	Version string `json:"version"`
+}
+
+func (c *AzureDevopsClient) GetPipelineRun(project string, pipelineID int64, runID int64) (run PipelineRun, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/pipelines/%v/runs/%v?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(int64ToString(pipelineID)),
+		url.QueryEscape(int64ToString(runID)),
+		url.QueryEscape(PipelineApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &run)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
@@ -0,0 +1,86 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// approvals api is only available as preview
+	PipelineApprovalApiVersion = "7.1-preview.1"
+)
+
+type PipelineApprovalList struct {
+	Count int                `json:"count"`
+	List  []PipelineApproval `json:"value"`
+}
+
+type PipelineApproval struct {
+	Id                   string    `json:"id"`
+	Status               string    `json:"status"`
+	CreatedOn            time.Time `json:"createdOn"`
+	LastModifiedOn       time.Time `json:"lastModifiedOn"`
+	MinRequiredApprovers int64     `json:"minRequiredApprovers"`
+
+	Steps []PipelineApprovalStep `json:"steps"`
+
+	Pipeline struct {
+		Id    string `json:"id"`
+		Name  string `json:"name"`
+		Owner struct {
+			Id   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"owner"`
+	} `json:"pipeline"`
+}
+
+type PipelineApprovalStep struct {
+	Status           string      `json:"status"`
+	AssignedApprover IdentifyRef `json:"assignedApprover"`
+	ActualApprover   IdentifyRef `json:"actualApprover"`
+	InitiatedOn      time.Time   `json:"initiatedOn"`
+	LastModifiedOn   time.Time   `json:"lastModifiedOn"`
+}
+
+func (a *PipelineApproval) IsPending() bool {
+	return strings.EqualFold(a.Status, "pending")
+}
+
+// ApprovedBy returns the identities which approved the approval steps
+func (a *PipelineApproval) ApprovedBy() (approverList []IdentifyRef) {
+	for _, step := range a.Steps {
+		if strings.EqualFold(step.Status, "approved") && step.ActualApprover.DisplayName != "" {
+			approverList = append(approverList, step.ActualApprover)
+		}
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) ListPipelineApprovals(project string) (list PipelineApprovalList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/pipelines/approvals?$expand=steps&$top=%v&api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(int64ToString(c.LimitApprovalsPerProject)),
+		url.QueryEscape(PipelineApprovalApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
@@ -0,0 +1,101 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	PolicyTypeCommitAuthorEmail = "Commit author email validation"
+	PolicyTypeFilePath          = "File path validation"
+)
+
+type PolicyConfigurationList struct {
+	Count int                   `json:"count"`
+	List  []PolicyConfiguration `json:"value"`
+}
+
+type PolicyConfiguration struct {
+	Id         int64 `json:"id"`
+	Revision   int64 `json:"revision"`
+	IsEnabled  bool  `json:"isEnabled"`
+	IsBlocking bool  `json:"isBlocking"`
+	IsDeleted  bool  `json:"isDeleted"`
+
+	Type struct {
+		Id          string `json:"id"`
+		DisplayName string `json:"displayName"`
+	} `json:"type"`
+
+	Settings struct {
+		Scope []struct {
+			// empty if the policy applies to all repositories of the project
+			RepositoryId string `json:"repositoryId"`
+			RefName      string `json:"refName"`
+			MatchKind    string `json:"matchKind"`
+		} `json:"scope"`
+
+		// commit author email validation
+		AuthorEmailPatterns []string `json:"authorEmailPatterns"`
+
+		// file path validation
+		FilenamePatterns []string `json:"filenamePatterns"`
+	} `json:"settings"`
+}
+
+// AppliesToRepository checks if the policy is scoped to the repository or to all repositories
+func (p *PolicyConfiguration) AppliesToRepository(repositoryId string) bool {
+	if len(p.Settings.Scope) == 0 {
+		return true
+	}
+
+	for _, scope := range p.Settings.Scope {
+		if scope.RepositoryId == "" || strings.EqualFold(scope.RepositoryId, repositoryId) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEffective checks if the policy is enabled and, for known policy types, has patterns configured
+func (p *PolicyConfiguration) IsEffective() bool {
+	if !p.IsEnabled || p.IsDeleted {
+		return false
+	}
+
+	switch {
+	case strings.EqualFold(p.Type.DisplayName, PolicyTypeCommitAuthorEmail):
+		return len(p.Settings.AuthorEmailPatterns) > 0
+	case strings.EqualFold(p.Type.DisplayName, PolicyTypeFilePath):
+		return len(p.Settings.FilenamePatterns) > 0
+	}
+
+	return true
+}
+
+func (c *AzureDevopsClient) ListPolicyConfigurations(project string) (list PolicyConfigurationList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/policy/configurations?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
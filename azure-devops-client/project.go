@@ -33,13 +33,13 @@ func (c *AzureDevopsClient) ListProjects() (list ProjectList, error error) {
 		c.LimitProject,
 		url.QueryEscape(c.ApiVersion),
 	)
-	response, err := c.rest().R().Get(url)
-	if err := c.checkResponse(response, err); err != nil {
+	body, err := c.getCached(CacheCategoryProjects, c.rest(), url)
+	if err != nil {
 		error = err
 		return
 	}
 
-	err = json.Unmarshal(response.Body(), &list)
+	err = json.Unmarshal(body, &list)
 	if err != nil {
 		error = err
 		return
@@ -35,9 +35,32 @@ type PullRequest struct {
 
 	IsDraft bool
 
+	// auto-complete is enabled if set
+	AutoCompleteSetBy   *IdentifyRef `json:"autoCompleteSetBy"`
+	MergeStatus         string       `json:"mergeStatus"`
+	MergeFailureMessage string       `json:"mergeFailureMessage"`
+
 	Links Links `json:"_links"`
 }
 
+func (p *PullRequest) MergeDuration() time.Duration {
+	return p.ClosedDate.Sub(p.CreationDate)
+}
+
+func (p *PullRequest) IsAutoComplete() bool {
+	return p.AutoCompleteSetBy != nil && p.AutoCompleteSetBy.Id != ""
+}
+
+// MergeBlockingReason returns the merge status if the merge is blocked (eg. conflicts or rejectedByPolicy)
+func (p *PullRequest) MergeBlockingReason() string {
+	switch p.MergeStatus {
+	case "", "notSet", "queued", "succeeded":
+		return ""
+	default:
+		return p.MergeStatus
+	}
+}
+
 type PullRequestReviewer struct {
 	Vote        int64
 	DisplayName string
@@ -121,3 +144,68 @@ func (c *AzureDevopsClient) ListPullrequest(project, repositoryId string) (list
 
 	return
 }
+
+func (c *AzureDevopsClient) ListCompletedPullrequest(project, repositoryId string) (list PullRequestList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/git/repositories/%v/pullrequests?api-version=%v&searchCriteria.status=completed&$top=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(repositoryId),
+		url.QueryEscape(c.ApiVersion),
+		url.QueryEscape(int64ToString(c.LimitPullRequestsPerRepository)),
+	)
+
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+type PullRequestIterationList struct {
+	Count int                    `json:"count"`
+	List  []PullRequestIteration `json:"value"`
+}
+
+type PullRequestIteration struct {
+	Id          int64     `json:"id"`
+	Reason      string    `json:"reason"`
+	CreatedDate time.Time `json:"createdDate"`
+}
+
+func (c *AzureDevopsClient) ListPullRequestIterations(project, repositoryId string, pullRequestId int64) (list PullRequestIterationList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/git/repositories/%v/pullRequests/%v/iterations?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(repositoryId),
+		url.QueryEscape(int64ToString(pullRequestId)),
+		url.QueryEscape(c.ApiVersion),
+	)
+
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
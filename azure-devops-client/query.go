@@ -4,12 +4,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 type Query struct {
 	Path string `json:"path"`
 }
 
+type QueryHierarchyItemList struct {
+	Count int                  `json:"count"`
+	List  []QueryHierarchyItem `json:"value"`
+}
+
+type QueryHierarchyItem struct {
+	Id          string               `json:"id"`
+	Name        string               `json:"name"`
+	Path        string               `json:"path"`
+	IsFolder    bool                 `json:"isFolder"`
+	IsPublic    bool                 `json:"isPublic"`
+	HasChildren bool                 `json:"hasChildren"`
+	Children    []QueryHierarchyItem `json:"children"`
+}
+
+// QueryList returns all (non folder) queries in the hierarchy
+func (q *QueryHierarchyItem) QueryList() (list []QueryHierarchyItem) {
+	for _, child := range q.Children {
+		if child.IsFolder {
+			list = append(list, child.QueryList()...)
+		} else {
+			list = append(list, child)
+		}
+	}
+
+	return
+}
+
 type WorkItemInfoList struct {
 	List []WorkItemInfo `json:"workItems"`
 }
@@ -29,7 +58,63 @@ func (c *AzureDevopsClient) QueryWorkItems(queryPath, projectId string) (list Wo
 		queryPath,
 		url.QueryEscape(c.ApiVersion),
 	)
-	response, err := c.rest().R().Get(url)
+	response, err := c.restQuery().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) GetQueryFolder(projectId, folderPath string, depth int) (folder QueryHierarchyItem, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	// folder paths are accepted as '\Shared Queries\Reports' or 'Shared Queries/Reports'
+	folderPathSegments := strings.Split(strings.Trim(strings.ReplaceAll(folderPath, `\`, "/"), "/"), "/")
+	for i, segment := range folderPathSegments {
+		folderPathSegments[i] = url.PathEscape(segment)
+	}
+
+	url := fmt.Sprintf(
+		"%v/_apis/wit/queries/%v?$depth=%v&api-version=%v",
+		url.QueryEscape(projectId),
+		strings.Join(folderPathSegments, "/"),
+		depth,
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.restQuery().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &folder)
+	if err != nil {
+		error = err
+	}
+
+	return
+}
+
+// ListQueries returns the root query folders ('My Queries' and 'Shared Queries') of the project up to depth
+func (c *AzureDevopsClient) ListQueries(projectId string, depth int) (list QueryHierarchyItemList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/wit/queries?$depth=%v&api-version=%v",
+		url.QueryEscape(projectId),
+		depth,
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.restQuery().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
 		error = err
 		return
@@ -47,9 +47,10 @@ type Release struct {
 }
 
 type ReleaseArtifact struct {
-	SourceId string `json:"sourceId"`
-	Type     string `json:"type"`
-	Alias    string `json:"alias"`
+	SourceId  string `json:"sourceId"`
+	Type      string `json:"type"`
+	Alias     string `json:"alias"`
+	IsPrimary bool   `json:"isPrimary"`
 
 	DefinitionReference struct {
 		Definition struct {
@@ -111,6 +112,9 @@ type ReleaseEnvironmentDeployStep struct {
 
 	ReleaseDeployPhases []ReleaseEnvironmentDeployStepPhase
 
+	PreDeploymentGates  ReleaseEnvironmentDeployStepGates `json:"preDeploymentGates"`
+	PostDeploymentGates ReleaseEnvironmentDeployStepGates `json:"postDeploymentGates"`
+
 	QueuedOn       time.Time
 	LastModifiedOn time.Time
 }
@@ -123,6 +127,37 @@ type ReleaseEnvironmentDeployStepPhase struct {
 	PhaseType string
 	Status    string
 	StartedOn time.Time `json:"startedOn"`
+
+	DeploymentJobs []struct {
+		Job ReleaseTask `json:"job"`
+	} `json:"deploymentJobs"`
+}
+
+type ReleaseEnvironmentDeployStepGates struct {
+	Id             int64     `json:"id"`
+	Status         string    `json:"status"`
+	StartedOn      time.Time `json:"startedOn"`
+	LastModifiedOn time.Time `json:"lastModifiedOn"`
+
+	// every gate sampling is a deployment job, the gates are its tasks
+	DeploymentJobs []struct {
+		Job   ReleaseTask   `json:"job"`
+		Tasks []ReleaseTask `json:"tasks"`
+	} `json:"deploymentJobs"`
+}
+
+type ReleaseTask struct {
+	Id         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	StartTime  time.Time `json:"startTime"`
+	FinishTime time.Time `json:"finishTime"`
+
+	Task struct {
+		Id      string `json:"id"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"task"`
 }
 
 type ReleaseEnvironmentApproval struct {
@@ -148,6 +183,92 @@ func (r *Release) QueueDuration() time.Duration {
 	return r.StartTime.Sub(r.QueueTime)
 }
 
+func (p *ReleaseEnvironmentDeployStepPhase) Duration() (duration time.Duration) {
+	var startTime, finishTime time.Time
+	for _, deploymentJob := range p.DeploymentJobs {
+		if startTime.IsZero() || (!deploymentJob.Job.StartTime.IsZero() && deploymentJob.Job.StartTime.Before(startTime)) {
+			startTime = deploymentJob.Job.StartTime
+		}
+
+		if deploymentJob.Job.FinishTime.After(finishTime) {
+			finishTime = deploymentJob.Job.FinishTime
+		}
+	}
+
+	if !startTime.IsZero() && !finishTime.IsZero() {
+		duration = finishTime.Sub(startTime)
+	}
+
+	return
+}
+
+func (g *ReleaseEnvironmentDeployStepGates) Duration() (duration time.Duration) {
+	if !g.StartedOn.IsZero() && !g.LastModifiedOn.IsZero() {
+		duration = g.LastModifiedOn.Sub(g.StartedOn)
+	}
+
+	return
+}
+
+// BlockingGates returns the gates of the latest evaluation which did not succeed while the gates are still pending or being evaluated
+func (g *ReleaseEnvironmentDeployStepGates) BlockingGates() (list []ReleaseTask) {
+	switch g.Status {
+	case "pending", "inProgress":
+	default:
+		return
+	}
+
+	latestJob := -1
+	for num, deploymentJob := range g.DeploymentJobs {
+		if latestJob < 0 || deploymentJob.Job.StartTime.After(g.DeploymentJobs[latestJob].Job.StartTime) {
+			latestJob = num
+		}
+	}
+
+	if latestJob >= 0 {
+		for _, task := range g.DeploymentJobs[latestJob].Tasks {
+			if task.Status != "succeeded" && task.Status != "skipped" {
+				list = append(list, task)
+			}
+		}
+	}
+
+	return
+}
+
+// GateType returns the name of the task definition (eg. AzureMonitor, InvokeRESTAPI, queryWorkItems) or the task name
+func (t *ReleaseTask) GateType() string {
+	if t.Task.Name != "" {
+		return t.Task.Name
+	}
+	return t.Name
+}
+
+func (c *AzureDevopsClient) GetRelease(project string, releaseId int64) (release Release, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/release/releases/%v?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(int64ToString(releaseId)),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.restVsrm().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &release)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
 func (c *AzureDevopsClient) ListReleases(project string, releaseDefinitionId int64) (list ReleaseList, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
@@ -199,3 +320,82 @@ func (c *AzureDevopsClient) ListReleaseHistory(project string, minTime time.Time
 
 	return
 }
+
+type ReleaseApprovalList struct {
+	Count int               `json:"count"`
+	List  []ReleaseApproval `json:"value"`
+}
+
+type ReleaseApproval struct {
+	Id           int64       `json:"id"`
+	ApprovalType string      `json:"approvalType"`
+	Status       string      `json:"status"`
+	Approver     IdentifyRef `json:"approver"`
+
+	Release struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"release"`
+
+	ReleaseDefinition struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"releaseDefinition"`
+
+	ReleaseEnvironment struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"releaseEnvironment"`
+
+	CreatedOn time.Time `json:"createdOn"`
+}
+
+// ListPendingReleaseApprovals lists the pending release approvals (follows continuation tokens up to LimitApprovalsPerProject)
+func (c *AzureDevopsClient) ListPendingReleaseApprovals(project string) (list ReleaseApprovalList, error error) {
+	continuationToken := ""
+	for {
+		page, nextContinuationToken, err := c.listPendingReleaseApprovals(project, c.LimitApprovalsPerProject-int64(len(list.List)), continuationToken)
+		if err != nil {
+			error = err
+			return
+		}
+
+		list.List = append(list.List, page.List...)
+		if nextContinuationToken == "" || int64(len(list.List)) >= c.LimitApprovalsPerProject {
+			break
+		}
+		continuationToken = nextContinuationToken
+	}
+	list.Count = len(list.List)
+
+	return
+}
+
+func (c *AzureDevopsClient) listPendingReleaseApprovals(project string, top int64, continuationToken string) (list ReleaseApprovalList, nextContinuationToken string, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/release/approvals?api-version=%v&statusFilter=pending&top=%v&continuationToken=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(c.ApiVersion),
+		url.QueryEscape(int64ToString(top)),
+		url.QueryEscape(continuationToken),
+	)
+
+	response, err := c.restVsrm().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	nextContinuationToken = response.Header().Get("x-ms-continuationtoken")
+
+	return
+}
@@ -36,17 +36,79 @@ type ReleaseDefinitionEnvironment struct {
 	} `json:"currentRelease"`
 
 	BadgeUrl string `json:"badgeUrl"`
+
+	// approval configuration (only included in release definition details)
+	PreDeployApprovals  ReleaseDefinitionApprovals `json:"preDeployApprovals"`
+	PostDeployApprovals ReleaseDefinitionApprovals `json:"postDeployApprovals"`
+}
+
+type ReleaseDefinitionApprovals struct {
+	Approvals []ReleaseDefinitionApprovalStep `json:"approvals"`
+}
+
+type ReleaseDefinitionApprovalStep struct {
+	Id          int64       `json:"id"`
+	Rank        int64       `json:"rank"`
+	IsAutomated bool        `json:"isAutomated"`
+	Approver    IdentifyRef `json:"approver"`
+}
+
+// ApproverCount returns the number of (manual) approvers
+func (a *ReleaseDefinitionApprovals) ApproverCount() (count int) {
+	for _, approval := range a.Approvals {
+		if !approval.IsAutomated && approval.Approver.Id != "" {
+			count++
+		}
+	}
+
+	return
+}
+
+// IsRequired returns true if at least one manual approval is configured
+func (a *ReleaseDefinitionApprovals) IsRequired() bool {
+	return a.ApproverCount() > 0
 }
 
 func (c *AzureDevopsClient) ListReleaseDefinitions(project string) (list ReleaseDefinitionList, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
 
+	pathFilter := ""
+	if c.ReleaseDefinitionPathFilter != "" {
+		pathFilter = "&path=" + url.QueryEscape(c.ReleaseDefinitionPathFilter)
+	}
+
 	url := fmt.Sprintf(
-		"%v/_apis/release/definitions?api-version=%v&isDeleted=false&$top=%v&$expand=environments,lastRelease",
+		"%v/_apis/release/definitions?api-version=%v&isDeleted=false&$top=%v&$expand=environments,lastRelease%v",
 		url.QueryEscape(project),
 		url.QueryEscape(c.ApiVersion),
 		url.QueryEscape(int64ToString(c.LimitReleaseDefinitionsPerProject)),
+		pathFilter,
+	)
+	body, err := c.getCached(CacheCategoryReleaseDefinitions, c.restVsrm(), url)
+	if err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) GetReleaseDefinition(project string, releaseDefinitionId int64) (releaseDefinition ReleaseDefinition, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/release/definitions/%v?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(int64ToString(releaseDefinitionId)),
+		url.QueryEscape(c.ApiVersion),
 	)
 	response, err := c.restVsrm().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
@@ -54,7 +116,7 @@ func (c *AzureDevopsClient) ListReleaseDefinitions(project string) (list Release
 		return
 	}
 
-	err = json.Unmarshal(response.Body(), &list)
+	err = json.Unmarshal(response.Body(), &releaseDefinition)
 	if err != nil {
 		error = err
 		return
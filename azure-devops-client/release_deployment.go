@@ -18,9 +18,10 @@ type ReleaseDeployment struct {
 	Name string
 
 	Release struct {
-		Id    int64
-		Name  string
-		Links Links `json:"_links"`
+		Id        int64
+		Name      string
+		Artifacts []ReleaseArtifact `json:"artifacts"`
+		Links     Links             `json:"_links"`
 	} `json:"release"`
 
 	ReleaseDefinition struct {
@@ -58,17 +59,43 @@ type ReleaseDeploymentEnvironment struct {
 	Name string
 }
 
-func (d *ReleaseDeployment) ApprovedBy() string {
-	var approverList []string
+// ApprovedBy returns the identities which approved the (manual) pre deployment approvals
+func (d *ReleaseDeployment) ApprovedBy() (approverList []IdentifyRef) {
 	for _, approval := range d.PreDeployApprovals {
 		if !approval.IsAutomated {
 			if approval.ApprovedBy.DisplayName != "" {
-				approverList = append(approverList, approval.ApprovedBy.DisplayName)
+				approverList = append(approverList, approval.ApprovedBy)
 			}
 		}
 	}
 
-	return strings.Join(approverList[:], ",")
+	return
+}
+
+// TriggeringBuildDefinitionId returns the build definition of the primary build artifact
+// (or the first build artifact if no build artifact is primary)
+func (d *ReleaseDeployment) TriggeringBuildDefinitionId() string {
+	artifactList := d.Release.Artifacts
+	if len(artifactList) == 0 {
+		artifactList = d.Artifacts
+	}
+
+	buildDefinitionId := ""
+	for _, artifact := range artifactList {
+		if !strings.EqualFold(artifact.Type, "build") {
+			continue
+		}
+
+		if artifact.IsPrimary {
+			return artifact.DefinitionReference.Definition.Id
+		}
+
+		if buildDefinitionId == "" {
+			buildDefinitionId = artifact.DefinitionReference.Definition.Id
+		}
+	}
+
+	return buildDefinitionId
 }
 
 func (d *ReleaseDeployment) QueuedOnTime() *time.Time {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,8 @@ type Repository struct {
 	Visibility string
 	Size       int64
 
+	DefaultBranch string `json:"defaultBranch"`
+
 	IsDisabled *bool `json:"isDisabled"`
 
 	Links Links `json:"_links"`
@@ -46,6 +49,56 @@ type RepositoryCommit struct {
 
 	Url       string
 	RemoteUrl string
+
+	// only included if requested (see RepositoryCommitSearchCriteria.IncludeStatuses)
+	Statuses []RepositoryCommitStatus `json:"statuses"`
+}
+
+type RepositoryCommitStatus struct {
+	Id           int64     `json:"id"`
+	State        string    `json:"state"`
+	CreationDate time.Time `json:"creationDate"`
+
+	Context struct {
+		Name  string `json:"name"`
+		Genre string `json:"genre"`
+	} `json:"context"`
+}
+
+// RepositoryCommitSearchCriteria filters the commits listed by ListCommits (empty values are not sent)
+type RepositoryCommitSearchCriteria struct {
+	FromDate *time.Time
+
+	// branch name (with or without refs/heads/ prefix)
+	Branch string
+
+	// maximum number of commits (api default if 0)
+	Top int64
+
+	// include the commit statuses (eg. external CI or policy checks)
+	IncludeStatuses bool
+}
+
+// ContextName returns the status context in the form '<genre>/<name>' (or '<name>' without genre)
+func (s *RepositoryCommitStatus) ContextName() string {
+	if s.Context.Genre != "" {
+		return s.Context.Genre + "/" + s.Context.Name
+	}
+
+	return s.Context.Name
+}
+
+// LatestStatuses returns the latest status per context of the commit
+func (c *RepositoryCommit) LatestStatuses() map[string]RepositoryCommitStatus {
+	list := map[string]RepositoryCommitStatus{}
+	for _, status := range c.Statuses {
+		contextName := status.ContextName()
+		if latestStatus, exists := list[contextName]; !exists || status.CreationDate.After(latestStatus.CreationDate) {
+			list[contextName] = status
+		}
+	}
+
+	return list
 }
 
 type RepositoryPushList struct {
@@ -55,6 +108,7 @@ type RepositoryPushList struct {
 
 type RepositoryPush struct {
 	PushId int64
+	Date   time.Time `json:"date"`
 }
 
 func (c *AzureDevopsClient) ListRepositories(project string) (list RepositoryList, error error) {
@@ -65,11 +119,52 @@ func (c *AzureDevopsClient) ListRepositories(project string) (list RepositoryLis
 		"%v/_apis/git/repositories",
 		url.QueryEscape(project),
 	)
+	body, err := c.getCached(CacheCategoryRepositories, c.rest(), url)
+	if err != nil {
+		error = err
+		return
+	}
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) ListCommits(project string, repository string, criteria RepositoryCommitSearchCriteria) (list RepositoryCommitList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	query := []string{}
+	if criteria.FromDate != nil {
+		query = append(query, "searchCriteria.fromDate="+url.QueryEscape(criteria.FromDate.Format(time.RFC3339)))
+	}
+	if criteria.Branch != "" {
+		query = append(query, "searchCriteria.itemVersion.version="+url.QueryEscape(strings.TrimPrefix(criteria.Branch, "refs/heads/")))
+	}
+	if criteria.Top > 0 {
+		query = append(query, "searchCriteria.$top="+url.QueryEscape(int64ToString(criteria.Top)))
+	}
+	if criteria.IncludeStatuses {
+		query = append(query, "searchCriteria.includeStatuses=true")
+	}
+	query = append(query, "api-version="+url.QueryEscape(c.ApiVersion))
+
+	url := fmt.Sprintf(
+		"%v/_apis/git/repositories/%s/commits?%v",
+		url.QueryEscape(project),
+		url.QueryEscape(repository),
+		strings.Join(query, "&"),
+	)
+
 	response, err := c.rest().R().Get(url)
 	if err := c.checkResponse(response, err); err != nil {
 		error = err
 		return
 	}
+
 	err = json.Unmarshal(response.Body(), &list)
 	if err != nil {
 		error = err
@@ -79,14 +174,15 @@ func (c *AzureDevopsClient) ListRepositories(project string) (list RepositoryLis
 	return
 }
 
-func (c *AzureDevopsClient) ListCommits(project string, repository string, fromDate time.Time) (list RepositoryCommitList, error error) {
+func (c *AzureDevopsClient) GetCommit(project string, repository string, commitId string) (commit RepositoryCommit, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
 
 	url := fmt.Sprintf(
-		"_apis/git/repositories/%s/commits?searchCriteria.fromDate=%s&api-version=%v",
+		"%v/_apis/git/repositories/%s/commits/%s?api-version=%v",
+		url.QueryEscape(project),
 		url.QueryEscape(repository),
-		url.QueryEscape(fromDate.Format(time.RFC3339)),
+		url.QueryEscape(commitId),
 		url.QueryEscape(c.ApiVersion),
 	)
 
@@ -96,7 +192,7 @@ func (c *AzureDevopsClient) ListCommits(project string, repository string, fromD
 		return
 	}
 
-	err = json.Unmarshal(response.Body(), &list)
+	err = json.Unmarshal(response.Body(), &commit)
 	if err != nil {
 		error = err
 		return
@@ -110,7 +206,8 @@ func (c *AzureDevopsClient) ListPushes(project string, repository string, fromDa
 	c.concurrencyLock()
 
 	url := fmt.Sprintf(
-		"_apis/git/repositories/%s/pushes?searchCriteria.fromDate=%s&api-version=%v",
+		"%v/_apis/git/repositories/%s/pushes?searchCriteria.fromDate=%s&api-version=%v",
+		url.QueryEscape(project),
 		url.QueryEscape(repository),
 		url.QueryEscape(fromDate.Format(time.RFC3339)),
 		url.QueryEscape(c.ApiVersion),
@@ -131,6 +228,32 @@ func (c *AzureDevopsClient) ListPushes(project string, repository string, fromDa
 	return
 }
 
+func (c *AzureDevopsClient) ListLatestPushes(project string, repository string) (list RepositoryPushList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/git/repositories/%s/pushes?$top=1&api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(repository),
+		url.QueryEscape(c.ApiVersion),
+	)
+
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
 func (r *Repository) Disabled() (ret bool) {
 	if r.IsDisabled != nil {
 		return *r.IsDisabled
@@ -0,0 +1,87 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// retention api is not available in older api versions
+	RetentionApiVersion = "6.0-preview.1"
+)
+
+type RetentionLeaseList struct {
+	Count int              `json:"count"`
+	List  []RetentionLease `json:"value"`
+}
+
+type RetentionLease struct {
+	LeaseId         int64  `json:"leaseId"`
+	OwnerId         string `json:"ownerId"`
+	DefinitionId    int64  `json:"definitionId"`
+	RunId           int64  `json:"runId"`
+	ProtectPipeline bool   `json:"protectPipeline"`
+}
+
+type ProjectRetentionSetting struct {
+	PurgeArtifacts               RetentionSetting  `json:"purgeArtifacts"`
+	PurgePullRequestRuns         RetentionSetting  `json:"purgePullRequestRuns"`
+	PurgeRuns                    RetentionSetting  `json:"purgeRuns"`
+	RetainRunsPerProtectedBranch *RetentionSetting `json:"retainRunsPerProtectedBranch"`
+}
+
+type RetentionSetting struct {
+	Min   int64 `json:"min"`
+	Max   int64 `json:"max"`
+	Value int64 `json:"value"`
+}
+
+func (c *AzureDevopsClient) ListRetentionLeases(project string, definitionId int64) (list RetentionLeaseList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/build/retention/leases?api-version=%v&definitionId=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(RetentionApiVersion),
+		definitionId,
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) GetProjectRetentionSetting(project string) (setting ProjectRetentionSetting, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/build/retention?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(RetentionApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &setting)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
@@ -0,0 +1,90 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type TeamList struct {
+	Count int    `json:"count"`
+	List  []Team `json:"value"`
+}
+
+type Team struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type TeamIterationList struct {
+	Count int             `json:"count"`
+	List  []TeamIteration `json:"value"`
+}
+
+type TeamIteration struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+
+	Attributes struct {
+		StartDate  *time.Time `json:"startDate"`
+		FinishDate *time.Time `json:"finishDate"`
+		TimeFrame  string     `json:"timeFrame"`
+	} `json:"attributes"`
+}
+
+func (c *AzureDevopsClient) ListTeams(project string) (list TeamList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"_apis/projects/%v/teams?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) ListTeamCurrentIterations(project string, team string) (list TeamIterationList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/%v/_apis/work/teamsettings/iterations?$timeframe=current&api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(team),
+		url.QueryEscape(c.ApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err == nil && response.StatusCode() == http.StatusNotFound {
+		// team has no current iteration (CurrentIterationDoesNotExistException)
+		return
+	}
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
@@ -0,0 +1,73 @@
+package AzureDevopsClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// variable group api is only available as preview
+	VariableGroupApiVersion = "5.1-preview.1"
+)
+
+type VariableGroupList struct {
+	Count int             `json:"count"`
+	List  []VariableGroup `json:"value"`
+}
+
+type VariableGroup struct {
+	Id        int64                    `json:"id"`
+	Name      string                   `json:"name"`
+	Type      string                   `json:"type"`
+	Variables map[string]VariableValue `json:"variables"`
+
+	ProviderData struct {
+		ServiceEndpointId string `json:"serviceEndpointId"`
+		Vault             string `json:"vault"`
+	} `json:"providerData"`
+}
+
+type VariableValue struct {
+	Value    string `json:"value"`
+	IsSecret bool   `json:"isSecret"`
+}
+
+func (g *VariableGroup) IsKeyVault() bool {
+	return strings.EqualFold(g.Type, "AzureKeyVault")
+}
+
+func (g *VariableGroup) SecretCount() (count int) {
+	for _, variable := range g.Variables {
+		if variable.IsSecret {
+			count++
+		}
+	}
+
+	return
+}
+
+func (c *AzureDevopsClient) ListVariableGroups(project string) (list VariableGroupList, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	url := fmt.Sprintf(
+		"%v/_apis/distributedtask/variablegroups?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(VariableGroupApiVersion),
+	)
+	response, err := c.rest().R().Get(url)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
@@ -2,35 +2,187 @@ package AzureDevopsClient
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
 )
 
+const (
+	WorkItemRelationChild = "System.LinkTypes.Hierarchy-Forward"
+
+	// maximum number of work items per workitemsbatch request
+	WorkItemBatchSize = 200
+)
+
+type WorkItemList struct {
+	Count int        `json:"count"`
+	List  []WorkItem `json:"value"`
+}
+
 type WorkItem struct {
-	Id     int64          `json:"id"`
-	Fields WorkItemFields `json:"fields"`
+	Id        int64              `json:"id"`
+	Fields    WorkItemFields     `json:"fields"`
+	Relations []WorkItemRelation `json:"relations"`
+
+	// all fields of the work item (including custom fields)
+	FieldValues map[string]interface{} `json:"-"`
+}
+
+type WorkItemRelation struct {
+	Rel string `json:"rel"`
+	Url string `json:"url"`
 }
 
 type WorkItemFields struct {
 	Title        string `json:"System.Title"`
 	Path         string `json:"System.AreaPath"`
+	WorkItemType string `json:"System.WorkItemType"`
+	State        string `json:"System.State"`
 	CreatedDate  string `json:"System.CreatedDate"`
 	AcceptedDate string `json:"Microsoft.VSTS.CodeReview.AcceptedDate"`
 	ResolvedDate string `json:"Microsoft.VSTS.Common.ResolvedDate"`
 	ClosedDate   string `json:"Microsoft.VSTS.Common.ClosedDate"`
 }
 
-func (c *AzureDevopsClient) GetWorkItem(workItemUrl string) (workItem WorkItem, error error) {
+func (c *AzureDevopsClient) GetWorkItem(project string, workItemUrl string) (workItem WorkItem, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	response, err := c.projectRequest(c.restQuery(), project).Get(workItemUrl)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	workItem, error = parseWorkItem(response.Body())
+	return
+}
+
+func (c *AzureDevopsClient) GetWorkItemWithRelations(project string, workItemUrl string) (workItem WorkItem, error error) {
+	defer c.concurrencyUnlock()
+	c.concurrencyLock()
+
+	response, err := c.projectRequest(c.restQuery(), project).SetQueryParam("$expand", "relations").Get(workItemUrl)
+	if err := c.checkResponse(response, err); err != nil {
+		error = err
+		return
+	}
+
+	workItem, error = parseWorkItem(response.Body())
+	return
+}
+
+// GetWorkItemsBatch fetches the fields of the work items by id, split into requests of WorkItemBatchSize ids
+func (c *AzureDevopsClient) GetWorkItemsBatch(project string, ids []int64, fields []string) (list WorkItemList, error error) {
+	for start := 0; start < len(ids); start += WorkItemBatchSize {
+		end := start + WorkItemBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch, err := c.getWorkItemsBatch(project, ids[start:end], fields)
+		if err != nil {
+			error = err
+			return
+		}
+
+		list.List = append(list.List, batch.List...)
+	}
+	list.Count = len(list.List)
+
+	return
+}
+
+func (c *AzureDevopsClient) getWorkItemsBatch(project string, ids []int64, fields []string) (list WorkItemList, error error) {
 	defer c.concurrencyUnlock()
 	c.concurrencyLock()
 
-	response, err := c.rest().R().Get(workItemUrl)
+	url := fmt.Sprintf(
+		"%v/_apis/wit/workitemsbatch?api-version=%v",
+		url.QueryEscape(project),
+		url.QueryEscape(c.ApiVersion),
+	)
+
+	payload := struct {
+		Ids    []int64  `json:"ids"`
+		Fields []string `json:"fields"`
+	}{
+		Ids:    ids,
+		Fields: fields,
+	}
+
+	req := c.projectRequest(c.restQuery(), project)
+	req.SetHeader("Content-Type", "application/json")
+	req.SetBody(payload)
+	response, err := req.Post(url)
 	if err := c.checkResponse(response, err); err != nil {
 		error = err
 		return
 	}
 
-	err = json.Unmarshal(response.Body(), &workItem)
+	err = json.Unmarshal(response.Body(), &list)
+	if err != nil {
+		error = err
+		return
+	}
+
+	return
+}
+
+func parseWorkItem(body []byte) (workItem WorkItem, error error) {
+	err := json.Unmarshal(body, &workItem)
+	if err != nil {
+		error = err
+		return
+	}
+
+	rawWorkItem := struct {
+		Fields map[string]interface{} `json:"fields"`
+	}{}
+	err = json.Unmarshal(body, &rawWorkItem)
 	if err != nil {
 		error = err
+		return
+	}
+	workItem.FieldValues = rawWorkItem.Fields
+
+	return
+}
+
+// FieldValue returns the value of a work item field as string (display name for identity fields)
+func (w *WorkItem) FieldValue(fieldName string) string {
+	switch value := w.FieldValues[fieldName].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case map[string]interface{}:
+		if displayName, ok := value["displayName"].(string); ok {
+			return displayName
+		}
+		return ""
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// ChildIds returns the ids of all child work items (requires relations)
+func (w *WorkItem) ChildIds() (list []int64) {
+	for _, relation := range w.Relations {
+		if relation.Rel != WorkItemRelationChild {
+			continue
+		}
+
+		// relation url ends with the work item id (.../_apis/wit/workItems/{id})
+		relationUrl, err := url.Parse(relation.Url)
+		if err != nil {
+			continue
+		}
+
+		if id, err := strconv.ParseInt(path.Base(relationUrl.Path), 10, 64); err == nil {
+			list = append(list, id)
+		}
 	}
 
 	return
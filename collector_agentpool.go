@@ -16,6 +16,7 @@ func (c *CollectorAgentPool) Run() {
 	c.Processor.Setup(c)
 	go func() {
 		for {
+			c.countScrape()
 			go func() {
 				c.Collect()
 			}()
@@ -28,9 +29,13 @@ func (c *CollectorAgentPool) Collect() {
 	var wg sync.WaitGroup
 	var wgCallback sync.WaitGroup
 
-	ctx := context.Background()
+	if !c.isServiceAvailable() {
+		return
+	}
 
-	callbackChannel := make(chan func())
+	ctx, collectionErrors := c.newCollectionContext()
+
+	callbackChannel := c.newCallbackChannel()
 
 	c.collectionStart()
 
@@ -44,18 +49,15 @@ func (c *CollectorAgentPool) Collect() {
 	wgCallback.Add(1)
 	go func() {
 		defer wgCallback.Done()
-		var callbackList []func()
-		for callback := range callbackChannel {
-			callbackList = append(callbackList, callback)
-		}
+		callbackList := c.receiveCallbacks(callbackChannel)
 
 		// reset metric values
 		c.Processor.Reset()
 
 		// process callbacks (set metrics)
-		for _, callback := range callbackList {
-			callback()
-		}
+		seriesCount, callbackDuration := c.processCallbacks(callbackList)
+		c.setSeriesCount(seriesCount)
+		c.setCallbackDuration(callbackDuration)
 	}()
 
 	// wait for all funcs
@@ -63,5 +65,8 @@ func (c *CollectorAgentPool) Collect() {
 	close(callbackChannel)
 	wgCallback.Wait()
 
+	// collection is up if no processor reported an error
+	c.setUp(!collectionErrors.Failed())
+
 	c.collectionFinish()
 }
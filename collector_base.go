@@ -1,13 +1,117 @@
 package main
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
 
 	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
 )
 
+const (
+	// buffer size of the callback channel
+	collectorCallbackQueueSize = 100
+)
+
+var (
+	collectorPrometheus struct {
+		callbackDuration  *prometheus.GaugeVec
+		overrunning       *prometheus.GaugeVec
+		projectLastScrape *prometheus.GaugeVec
+		up                *prometheus.GaugeVec
+		scrapeCount       *prometheus.CounterVec
+		seriesCount       *prometheus.GaugeVec
+	}
+)
+
+type (
+	// collectorSeriesList is a metric list (MetricList or HashedMetricList) set by a callback
+	collectorSeriesList interface {
+		GetList() []prometheusCommon.MetricRow
+	}
+
+	// collectionErrors counts the errors of a collection (reported by processors via logError)
+	collectionErrors struct {
+		count int64
+	}
+
+	collectionErrorsContextKey struct{}
+)
+
+func initCollectorMetrics() {
+	collectorPrometheus.callbackDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_collector_callback_duration_seconds",
+			Help: "Azure DevOps collector duration of processing the callbacks (setting metrics) of the last collection",
+		},
+		[]string{
+			"name",
+		},
+	)
+	prometheus.MustRegister(collectorPrometheus.callbackDuration)
+
+	collectorPrometheus.overrunning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_collector_overrunning",
+			Help: "Azure DevOps collector last collection took longer than the scrape time",
+		},
+		[]string{
+			"name",
+		},
+	)
+	prometheus.MustRegister(collectorPrometheus.overrunning)
+
+	collectorPrometheus.projectLastScrape = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_project_last_scrape_timestamp_seconds",
+			Help: "Azure DevOps collector last time the collection of the project finished",
+		},
+		[]string{
+			"name",
+			"projectID",
+		},
+	)
+	prometheus.MustRegister(collectorPrometheus.projectLastScrape)
+
+	collectorPrometheus.up = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_collector_up",
+			Help: "Azure DevOps collector last collection was successful (no errors while fetching data)",
+		},
+		[]string{
+			"name",
+		},
+	)
+	prometheus.MustRegister(collectorPrometheus.up)
+
+	collectorPrometheus.scrapeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "azure_devops_collector_scrape_total",
+			Help: "Azure DevOps collector number of started collections (including skipped and failed ones)",
+		},
+		[]string{
+			"name",
+		},
+	)
+	prometheus.MustRegister(collectorPrometheus.scrapeCount)
+
+	collectorPrometheus.seriesCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_collector_series_count",
+			Help: "Azure DevOps collector number of gauge series set by the last collection",
+		},
+		[]string{
+			"name",
+		},
+	)
+	prometheus.MustRegister(collectorPrometheus.seriesCount)
+}
+
 type CollectorBase struct {
 	Name       string
 	scrapeTime *time.Duration
@@ -17,6 +121,10 @@ type CollectorBase struct {
 	LastScrapeDuration  *time.Duration
 	collectionStartTime *time.Time
 	collectionLastTime  *time.Time
+
+	// series set by the callbacks currently processed (see processCallbacks)
+	seriesLock  sync.Mutex
+	seriesCount int64
 }
 
 func (c *CollectorBase) Init() {
@@ -36,6 +144,16 @@ func (c *CollectorBase) GetAzureProjects() (projects []devopsClient.Project) {
 	return AzureDevopsServiceDiscovery.ProjectList()
 }
 
+func (c *CollectorBase) isServiceAvailable() bool {
+	if !AzureDevopsClient.IsServiceAvailable() {
+		c.logger.Warn("AzureDevOps service is unavailable, skipping")
+		c.setUp(false)
+		return false
+	}
+
+	return true
+}
+
 func (c *CollectorBase) collectionStart() {
 	startTime := time.Now()
 	c.collectionStartTime = &startTime
@@ -54,10 +172,105 @@ func (c *CollectorBase) collectionFinish() {
 
 	c.collectionLastTime = c.collectionStartTime
 
+	overrunning := 0.0
+	if *c.LastScrapeDuration > *c.GetScrapeTime() {
+		overrunning = 1
+		c.logger.Warnf("collection took longer (%v) than scrape time (%v)", c.LastScrapeDuration.String(), c.GetScrapeTime().String())
+	}
+	collectorPrometheus.overrunning.With(prometheus.Labels{
+		"name": c.Name,
+	}).Set(overrunning)
+
 	c.logger.WithField("duration", c.LastScrapeDuration.Seconds()).Infof("finished metrics collection (duration: %v)", c.LastScrapeDuration)
 }
 
+func (c *CollectorBase) newCallbackChannel() chan func() {
+	return make(chan func(), collectorCallbackQueueSize)
+}
+
+// receiveCallbacks reads all callbacks until the channel is closed, callbacks are processed afterwards (see processCallbacks)
+func (c *CollectorBase) receiveCallbacks(callbackChannel chan func()) (callbackList []func()) {
+	for callback := range callbackChannel {
+		callbackList = append(callbackList, callback)
+	}
+
+	return
+}
+
+// newCollectionContext returns the context of a collection which tracks the errors of the processors
+func (c *CollectorBase) newCollectionContext() (context.Context, *collectionErrors) {
+	collectionErrors := &collectionErrors{}
+	return context.WithValue(context.Background(), collectionErrorsContextKey{}, collectionErrors), collectionErrors
+}
+
+// collectionFailed marks the collection of the context as failed
+func collectionFailed(ctx context.Context) {
+	if collectionErrors, ok := ctx.Value(collectionErrorsContextKey{}).(*collectionErrors); ok {
+		atomic.AddInt64(&collectionErrors.count, 1)
+	}
+}
+
+// Failed returns true if any processor reported an error during the collection
+func (e *collectionErrors) Failed() bool {
+	return atomic.LoadInt64(&e.count) > 0
+}
+
+// setUp sets the state of the last collection (1 = successful, no errors while fetching data)
+func (c *CollectorBase) setUp(up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+
+	collectorPrometheus.up.With(prometheus.Labels{
+		"name": c.Name,
+	}).Set(value)
+}
+
+// countScrape counts every collection attempt of the run loop
+func (c *CollectorBase) countScrape() {
+	collectorPrometheus.scrapeCount.With(prometheus.Labels{
+		"name": c.Name,
+	}).Inc()
+}
+
 func (c *CollectorBase) sleepUntilNextCollection() {
 	c.logger.Debugf("sleeping %v", c.GetScrapeTime().String())
 	time.Sleep(*c.GetScrapeTime())
 }
+
+// processCallbacks runs the callbacks (sets metrics) and returns the number of series set by them and the processing duration
+func (c *CollectorBase) processCallbacks(callbackList []func()) (int64, time.Duration) {
+	c.seriesLock.Lock()
+	defer c.seriesLock.Unlock()
+
+	startTime := time.Now()
+
+	c.seriesCount = 0
+	for _, callback := range callbackList {
+		callback()
+	}
+
+	return c.seriesCount, time.Since(startTime)
+}
+
+// countSeries counts the series of the metric lists, must only be called by callbacks
+func (c *CollectorBase) countSeries(lists ...collectorSeriesList) {
+	for _, list := range lists {
+		c.seriesCount += int64(len(list.GetList()))
+	}
+}
+
+// setCallbackDuration sets the duration of processing the callbacks of the last collection
+func (c *CollectorBase) setCallbackDuration(duration time.Duration) {
+	collectorPrometheus.callbackDuration.With(prometheus.Labels{
+		"name": c.Name,
+	}).Set(duration.Seconds())
+}
+
+// setSeriesCount sets the number of series of the last collection
+func (c *CollectorBase) setSeriesCount(count int64) {
+	collectorPrometheus.seriesCount.With(prometheus.Labels{
+		"name": c.Name,
+	}).Set(float64(count))
+}
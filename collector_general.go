@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"sync"
 )
 
@@ -15,6 +14,7 @@ func (c *CollectorGeneral) Run() {
 	c.Processor.Setup(c)
 	go func() {
 		for {
+			c.countScrape()
 			go func() {
 				c.Collect()
 			}()
@@ -27,14 +27,19 @@ func (c *CollectorGeneral) Collect() {
 	var wg sync.WaitGroup
 	var wgCallback sync.WaitGroup
 
+	if !c.isServiceAvailable() {
+		return
+	}
+
 	if len(c.GetAzureProjects()) == 0 {
 		c.logger.Info("no projects found, skipping")
+		c.setUp(false)
 		return
 	}
 
-	ctx := context.Background()
+	ctx, collectionErrors := c.newCollectionContext()
 
-	callbackChannel := make(chan func())
+	callbackChannel := c.newCallbackChannel()
 
 	c.collectionStart()
 
@@ -48,18 +53,15 @@ func (c *CollectorGeneral) Collect() {
 	wgCallback.Add(1)
 	go func() {
 		defer wgCallback.Done()
-		var callbackList []func()
-		for callback := range callbackChannel {
-			callbackList = append(callbackList, callback)
-		}
+		callbackList := c.receiveCallbacks(callbackChannel)
 
 		// reset metric values
 		c.Processor.Reset()
 
 		// process callbacks (set metrics)
-		for _, callback := range callbackList {
-			callback()
-		}
+		seriesCount, callbackDuration := c.processCallbacks(callbackList)
+		c.setSeriesCount(seriesCount)
+		c.setCallbackDuration(callbackDuration)
 	}()
 
 	// wait for all funcs
@@ -67,5 +69,8 @@ func (c *CollectorGeneral) Collect() {
 	close(callbackChannel)
 	wgCallback.Wait()
 
+	// collection is up if no processor reported an error
+	c.setUp(!collectionErrors.Failed())
+
 	c.collectionFinish()
 }
@@ -8,8 +8,8 @@ import (
 
 type CollectorProcessorQueryInterface interface {
 	Setup(collector *CollectorQuery)
-	Reset()
-	Collect(ctx context.Context, contextLogger *log.Entry, callback chan<- func())
+	Reset(query *querySpec)
+	Collect(ctx context.Context, contextLogger *log.Entry, callback chan<- func(), query *querySpec)
 }
 
 type CollectorProcessorQuery struct {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
 	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
@@ -19,6 +20,7 @@ func (c *CollectorProject) Run() {
 	c.Processor.Setup(c)
 	go func() {
 		for {
+			c.countScrape()
 			go func() {
 				c.Collect()
 			}()
@@ -31,14 +33,19 @@ func (c *CollectorProject) Collect() {
 	var wg sync.WaitGroup
 	var wgCallback sync.WaitGroup
 
+	if !c.isServiceAvailable() {
+		return
+	}
+
 	if c.GetAzureProjects() == nil {
 		c.logger.Info("no projects found, skipping")
+		c.setUp(false)
 		return
 	}
 
-	ctx := context.Background()
+	ctx, collectionErrors := c.newCollectionContext()
 
-	callbackChannel := make(chan func())
+	callbackChannel := c.newCallbackChannel()
 
 	c.collectionStart()
 
@@ -50,6 +57,11 @@ func (c *CollectorProject) Collect() {
 				"project": project.Name,
 			})
 			c.Processor.Collect(ctx, contextLogger, callbackChannel, project)
+
+			collectorPrometheus.projectLastScrape.With(prometheus.Labels{
+				"name":      c.Name,
+				"projectID": project.Id,
+			}).SetToCurrentTime()
 		}(ctx, callbackChannel, project)
 	}
 
@@ -57,18 +69,15 @@ func (c *CollectorProject) Collect() {
 	wgCallback.Add(1)
 	go func() {
 		defer wgCallback.Done()
-		var callbackList []func()
-		for callback := range callbackChannel {
-			callbackList = append(callbackList, callback)
-		}
+		callbackList := c.receiveCallbacks(callbackChannel)
 
 		// reset metric values
 		c.Processor.Reset()
 
 		// process callbacks (set metrics)
-		for _, callback := range callbackList {
-			callback()
-		}
+		seriesCount, callbackDuration := c.processCallbacks(callbackList)
+		c.setSeriesCount(seriesCount)
+		c.setCallbackDuration(callbackDuration)
 	}()
 
 	// wait for all funcs
@@ -76,5 +85,8 @@ func (c *CollectorProject) Collect() {
 	close(callbackChannel)
 	wgCallback.Wait()
 
+	// collection is up if no processor reported an error
+	c.setUp(!collectionErrors.Failed())
+
 	c.collectionFinish()
 }
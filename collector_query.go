@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"sync"
 )
 
@@ -9,53 +10,75 @@ type CollectorQuery struct {
 	CollectorBase
 
 	Processor CollectorProcessorQueryInterface
-	QueryList []string
+	QueryList []*querySpec
+
+	// one scheduler per query (named '<collector>/<query>'), each reports its own collector metrics
+	SchedulerList []*CollectorBase
 }
 
 func (c *CollectorQuery) Run() {
 	c.Processor.Setup(c)
-	go func() {
-		for {
-			go func() {
-				c.Collect()
-			}()
-			c.sleepUntilNextCollection()
+
+	// each query is scheduled on its own interval
+	for _, query := range c.QueryList {
+		scheduler := &CollectorBase{
+			Name: fmt.Sprintf("%v/%v", c.Name, query.QueryPath),
 		}
-	}()
+		scheduler.Init()
+		scheduler.logger = scheduler.logger.WithField("query", query.QueryPath)
+		scheduler.SetScrapeTime(*c.GetScrapeTime())
+		if query.Interval != nil {
+			scheduler.SetScrapeTime(*query.Interval)
+		}
+		c.SchedulerList = append(c.SchedulerList, scheduler)
+	}
+
+	for num, query := range c.QueryList {
+		go func(scheduler *CollectorBase, query *querySpec) {
+			for {
+				scheduler.countScrape()
+				go func() {
+					c.Collect(scheduler, query)
+				}()
+				scheduler.sleepUntilNextCollection()
+			}
+		}(c.SchedulerList[num], query)
+	}
 }
 
-func (c *CollectorQuery) Collect() {
+func (c *CollectorQuery) Collect(scheduler *CollectorBase, query *querySpec) {
 	var wg sync.WaitGroup
 	var wgCallback sync.WaitGroup
 
-	ctx := context.Background()
+	if !scheduler.isServiceAvailable() {
+		return
+	}
+
+	ctx, collectionErrors := scheduler.newCollectionContext()
 
-	callbackChannel := make(chan func())
+	callbackChannel := scheduler.newCallbackChannel()
 
-	c.collectionStart()
+	scheduler.collectionStart()
 
 	wg.Add(1)
 	go func(ctx context.Context, callback chan<- func()) {
 		defer wg.Done()
-		c.Processor.Collect(ctx, c.logger, callbackChannel)
+		c.Processor.Collect(ctx, scheduler.logger, callbackChannel, query)
 	}(ctx, callbackChannel)
 
 	// collect metrics (callbacks) and process them
 	wgCallback.Add(1)
 	go func() {
 		defer wgCallback.Done()
-		var callbackList []func()
-		for callback := range callbackChannel {
-			callbackList = append(callbackList, callback)
-		}
+		callbackList := scheduler.receiveCallbacks(callbackChannel)
 
 		// reset metric values
-		c.Processor.Reset()
+		c.Processor.Reset(query)
 
 		// process callbacks (set metrics)
-		for _, callback := range callbackList {
-			callback()
-		}
+		seriesCount, callbackDuration := c.processCallbacks(callbackList)
+		scheduler.setSeriesCount(seriesCount)
+		scheduler.setCallbackDuration(callbackDuration)
 	}()
 
 	// wait for all funcs
@@ -63,5 +86,8 @@ func (c *CollectorQuery) Collect() {
 	close(callbackChannel)
 	wgCallback.Wait()
 
-	c.collectionFinish()
+	// collection is up if no processor reported an error
+	scheduler.setUp(!collectionErrors.Failed())
+
+	scheduler.collectionFinish()
 }
@@ -14,21 +14,28 @@ type (
 			Debug   bool `           long:"debug"        env:"DEBUG"    description:"debug mode"`
 			Verbose bool `short:"v"  long:"verbose"      env:"VERBOSE"  description:"verbose mode"`
 			LogJson bool `           long:"log.json"     env:"LOG_JSON" description:"Switch log output to json format"`
+
+			SampleInterval time.Duration `long:"log.sample-interval"  env:"LOG_SAMPLE_INTERVAL"  description:"Log identical collector errors once per interval with a summary of repetitions (time.Duration, 0 = disabled)"  default:"0"`
 		}
 
 		// scrape time settings
 		Scrape struct {
-			Time              time.Duration  `long:"scrape.time"                  env:"SCRAPE_TIME"                    description:"Default scrape time (time.duration)"                       default:"30m"`
-			TimeProjects      *time.Duration `long:"scrape.time.projects"         env:"SCRAPE_TIME_PROJECTS"           description:"Scrape time for project metrics (time.duration)"`
-			TimeRepository    *time.Duration `long:"scrape.time.repository"       env:"SCRAPE_TIME_REPOSITORY"         description:"Scrape time for repository metrics (time.duration)"`
-			TimeBuild         *time.Duration `long:"scrape.time.build"            env:"SCRAPE_TIME_BUILD"              description:"Scrape time for build metrics (time.duration)"`
-			TimeRelease       *time.Duration `long:"scrape.time.release"          env:"SCRAPE_TIME_RELEASE"            description:"Scrape time for release metrics (time.duration)"`
-			TimeDeployment    *time.Duration `long:"scrape.time.deployment"       env:"SCRAPE_TIME_DEPLOYMENT"         description:"Scrape time for deployment metrics (time.duration)"`
-			TimePullRequest   *time.Duration `long:"scrape.time.pullrequest"      env:"SCRAPE_TIME_PULLREQUEST"        description:"Scrape time for pullrequest metrics  (time.duration)"`
-			TimeStats         *time.Duration `long:"scrape.time.stats"            env:"SCRAPE_TIME_STATS"              description:"Scrape time for stats metrics  (time.duration)"`
-			TimeResourceUsage *time.Duration `long:"scrape.time.resourceusage"    env:"SCRAPE_TIME_RESOURCEUSAGE"      description:"Scrape time for resourceusage metrics  (time.duration)"`
-			TimeQuery         *time.Duration `long:"scrape.time.query"            env:"SCRAPE_TIME_QUERY"              description:"Scrape time for query results  (time.duration)"`
-			TimeLive          *time.Duration `long:"scrape.time.live"             env:"SCRAPE_TIME_LIVE"               description:"Scrape time for live metrics (time.duration)"              default:"30s"`
+			Time               time.Duration  `long:"scrape.time"                  env:"SCRAPE_TIME"                    description:"Default scrape time (time.duration)"                       default:"30m"`
+			TimeProjects       *time.Duration `long:"scrape.time.projects"         env:"SCRAPE_TIME_PROJECTS"           description:"Scrape time for project metrics (time.duration)"`
+			TimeRepository     *time.Duration `long:"scrape.time.repository"       env:"SCRAPE_TIME_REPOSITORY"         description:"Scrape time for repository metrics (time.duration)"`
+			TimeBuild          *time.Duration `long:"scrape.time.build"            env:"SCRAPE_TIME_BUILD"              description:"Scrape time for build metrics (time.duration)"`
+			TimeRelease        *time.Duration `long:"scrape.time.release"          env:"SCRAPE_TIME_RELEASE"            description:"Scrape time for release metrics (time.duration)"`
+			TimeDeployment     *time.Duration `long:"scrape.time.deployment"       env:"SCRAPE_TIME_DEPLOYMENT"         description:"Scrape time for deployment metrics (time.duration)"`
+			TimePullRequest    *time.Duration `long:"scrape.time.pullrequest"      env:"SCRAPE_TIME_PULLREQUEST"        description:"Scrape time for pullrequest metrics  (time.duration)"`
+			TimeStats          *time.Duration `long:"scrape.time.stats"            env:"SCRAPE_TIME_STATS"              description:"Scrape time for stats metrics  (time.duration)"`
+			TimeResourceUsage  *time.Duration `long:"scrape.time.resourceusage"    env:"SCRAPE_TIME_RESOURCEUSAGE"      description:"Scrape time for resourceusage metrics  (time.duration)"`
+			TimeQuery          *time.Duration `long:"scrape.time.query"            env:"SCRAPE_TIME_QUERY"              description:"Scrape time for query results  (time.duration)"`
+			TimeApproval       *time.Duration `long:"scrape.time.approval"         env:"SCRAPE_TIME_APPROVAL"           description:"Scrape time for pipeline approval metrics (time.duration)"`
+			TimeDashboard      *time.Duration `long:"scrape.time.dashboard"        env:"SCRAPE_TIME_DASHBOARD"          description:"Scrape time for dashboard metrics (time.duration)"`
+			TimeTeam           *time.Duration `long:"scrape.time.team"             env:"SCRAPE_TIME_TEAM"               description:"Scrape time for team metrics (time.duration)"`
+			TimeQueryInventory *time.Duration `long:"scrape.time.queryinventory"   env:"SCRAPE_TIME_QUERYINVENTORY"     description:"Scrape time for query inventory metrics (time.duration)"`
+			TimeExtension      *time.Duration `long:"scrape.time.extension"        env:"SCRAPE_TIME_EXTENSION"          description:"Scrape time for extension metrics (time.duration)"`
+			TimeLive           *time.Duration `long:"scrape.time.live"             env:"SCRAPE_TIME_LIVE"               description:"Scrape time for live metrics (time.duration)"              default:"30s"`
 		}
 
 		// summary options
@@ -41,8 +48,11 @@ type (
 			Url             *string `long:"azuredevops.url"                     env:"AZURE_DEVOPS_URL"               description:"Azure DevOps url (empty if hosted by microsoft)"`
 			AccessToken     string  `long:"azuredevops.access-token"            env:"AZURE_DEVOPS_ACCESS_TOKEN"      description:"Azure DevOps access token" json:"-"`
 			AccessTokenFile *string `long:"azuredevops.access-token-file"       env:"AZURE_DEVOPS_ACCESS_TOKEN_FILE" description:"Azure DevOps access token (from file)"`
-			Organisation    string  `long:"azuredevops.organisation"            env:"AZURE_DEVOPS_ORGANISATION"      description:"Azure DevOps organization" required:"true"`
-			ApiVersion      string  `long:"azuredevops.apiversion"              env:"AZURE_DEVOPS_APIVERSION"        description:"Azure DevOps API version"  default:"5.1"`
+			AuthUsername    string  `long:"azuredevops.auth-username"           env:"AZURE_DEVOPS_AUTH_USERNAME"     description:"Username for basic auth with access token (ignored by Azure DevOps, eg. required by proxies)"`
+
+			ProjectAccessTokens []string `long:"azuredevops.project-access-token"  env:"AZURE_DEVOPS_PROJECT_ACCESS_TOKEN"  env-delim:" "  description:"Access token for requests of a project in the form '<projectId>=<token>' (requests of other projects and organization wide requests use azuredevops.access-token)" json:"-"`
+			Organisation        string   `long:"azuredevops.organisation"            env:"AZURE_DEVOPS_ORGANISATION"      description:"Azure DevOps organization" required:"true"`
+			ApiVersion          string   `long:"azuredevops.apiversion"              env:"AZURE_DEVOPS_APIVERSION"        description:"Azure DevOps API version"  default:"5.1"`
 
 			// agentpool
 			AgentPoolIdList *[]int64 `long:"azuredevops.agentpool"  env:"AZURE_DEVOPS_AGENTPOOL"  env-delim:" "   description:"Enable scrape metrics for agent pool (IDs)"`
@@ -51,18 +61,123 @@ type (
 			FilterProjects    []string `long:"whitelist.project"    env:"AZURE_DEVOPS_FILTER_PROJECT"    env-delim:" "   description:"Filter projects (UUIDs)"`
 			BlacklistProjects []string `long:"blacklist.project"    env:"AZURE_DEVOPS_BLACKLIST_PROJECT" env-delim:" "   description:"Filter projects (UUIDs)"`
 
+			// release definition settings
+			ReleaseDefinitionPathFilter string `long:"releasedefinition.path"  env:"AZURE_DEVOPS_RELEASEDEFINITION_PATH"  description:"Only collect release definitions (and their releases and deployments) under this folder path (eg. '\\Production')"`
+
 			// query settings
-			QueriesWithProjects []string `long:"list.query"    env:"AZURE_DEVOPS_QUERIES"    env-delim:" "   description:"Pairs of query and project UUIDs in the form: '<queryId>@<projectId>'"`
+			QueriesWithProjects []string `long:"list.query"    env:"AZURE_DEVOPS_QUERIES"    env-delim:" "   description:"Pairs of query and project UUIDs in the form: '<queryId>@<projectId>' or 'folder:<folderPath>@<projectId>' (options: ';areaPath=<path>', ';areaPathCount=<bool>', ';interval=<time.duration>', ';children=<bool>', ';groupBy=<field>[,<field>]', ';backlog=<bool>', ';depth=<1-2>' for folders)"`
+		}
+
+		// project settings
+		Project struct {
+			Retention        bool  `long:"project.retention"            env:"PROJECT_RETENTION"             description:"Enable retention collector (retention leases and settings per project, one lease request per build definition, uses scrape.time.projects)"`
+			RetentionMinDays int64 `long:"project.retention.min-days"   env:"PROJECT_RETENTION_MIN_DAYS"    description:"Minimum days pipeline runs have to be retained to comply with retention policy"  default:"30"`
+		}
+
+		// repository settings
+		Repository struct {
+			Contributors         bool          `long:"repository.contributors"           env:"REPOSITORY_CONTRIBUTORS"            description:"Collect number of distinct contributors per repository (additional request per repository)"`
+			ContributorsDuration time.Duration `long:"repository.contributors.duration"  env:"REPOSITORY_CONTRIBUTORS_DURATION"   description:"Time (time.Duration) how long the exporter should look back for contributors"  default:"720h"`
+			LastPush             bool          `long:"repository.lastpush"               env:"REPOSITORY_LASTPUSH"                description:"Collect last push timestamp per repository (additional request per repository)"`
+
+			CommitStatuses        bool  `long:"repository.commit-statuses"          env:"REPOSITORY_COMMIT_STATUSES"          description:"Collect commit statuses (eg. posted by external CI) of latest commits on default branch (additional request per repository)"`
+			CommitStatusesCommits int64 `long:"repository.commit-statuses.commits"  env:"REPOSITORY_COMMIT_STATUSES_COMMITS"  description:"Number of latest commits on default branch used for commit statuses"  default:"10"`
+
+			Policies    bool     `long:"repository.policies"       env:"REPOSITORY_POLICIES"       description:"Collect if policy types are enabled per repository (additional request per project)"`
+			PolicyTypes []string `long:"repository.policies.type"  env:"REPOSITORY_POLICIES_TYPE"  env-delim:","  description:"Policy types (display name) collected per repository"  default:"Commit author email validation"  default:"File path validation"`
+		}
+
+		// build settings
+		Build struct {
+			DefinitionProperties    bool `long:"build.definition-properties"       env:"BUILD_DEFINITION_PROPERTIES"       description:"Collect triggers and demands of build definitions (requests all definition properties)"`
+			HostedJobsPerDefinition bool `long:"build.hosted-jobs.per-definition"  env:"BUILD_HOSTED_JOBS_PER_DEFINITION"  description:"Break down running build jobs on hosted agent pools by build definition (classic release deployments are not counted)"`
+			TriggerLatency          bool `long:"build.trigger-latency"             env:"BUILD_TRIGGER_LATENCY"             description:"Collect latency from source commit to build start of latest CI triggered build per definition (additional request per definition)"`
+			WithOutputs             bool `long:"build.with-outputs"                env:"BUILD_WITH_OUTPUTS"                description:"Only collect completed builds which published artifacts or test results (additional requests per completed build)"`
+
+			StaleDuration time.Duration `long:"build.stale-duration"  env:"BUILD_STALE_DURATION"  description:"Time (time.Duration) without successful build after which an enabled build definition is considered stale (0 = disabled, additional request per project)"  default:"0"`
+		}
+
+		// deployment settings
+		Deployment struct {
+			Phases        bool `long:"deployment.phases"  env:"DEPLOYMENT_PHASES"  description:"Collect deployment phase durations (additional request per release)"`
+			BlockingGates bool `long:"deployment.blocking-gates"  env:"DEPLOYMENT_BLOCKING_GATES"  description:"Collect gates blocking deployments (additional request per release)"`
+
+			ProdEnvironment string `long:"deployment.prod-environment"  env:"DEPLOYMENT_PROD_ENVIRONMENT"  description:"Regular expression matching production environment names, enables count of release definitions deployed to production today"`
+		}
+
+		// agentpool settings
+		AgentPool struct {
+			Capabilities bool `long:"agentpool.capabilities"  env:"AGENTPOOL_CAPABILITIES"  description:"Collect agent capabilities and jobs with demands not satisfied by any online agent"`
+		}
+
+		// pipeline settings
+		Pipeline struct {
+			Dependencies bool `long:"pipeline.dependencies"  env:"PIPELINE_DEPENDENCIES"  description:"Collect pipeline and repository resources of latest pipeline runs (additional request per pipeline)"`
+			Approvals    bool `long:"pipeline.approvals"     env:"PIPELINE_APPROVALS"     description:"Enable pipeline (yaml) approval collector (uses scrape.time.approval)"`
+		}
+
+		// pullrequest settings
+		PullRequest struct {
+			MergeDuration bool `long:"pullrequest.mergeduration"  env:"PULLREQUEST_MERGEDURATION"  description:"Collect merge duration of completed pullrequests (additional request per repository)"`
+			Iterations    bool `long:"pullrequest.iterations"     env:"PULLREQUEST_ITERATIONS"     description:"Collect number of iterations (updates) of active pullrequests (additional request per pullrequest)"`
+		}
+
+		// release settings
+		Release struct {
+			ArtifactAge      bool `long:"release.artifactage"        env:"RELEASE_ARTIFACTAGE"        description:"Collect age of build artifacts of latest release per definition (additional request per artifact)"`
+			PendingApprovers bool `long:"release.pending-approvers"  env:"RELEASE_PENDING_APPROVERS"  description:"Collect approvers of pending deployment approvals (additional request per project)"`
+			ApprovalConfig   bool `long:"release.approval-config"    env:"RELEASE_APPROVAL_CONFIG"    description:"Collect approval configuration of release definition environments (additional request per release definition)"`
+		}
+
+		// dashboard settings
+		Dashboard struct {
+			Inventory       bool `long:"dashboard.inventory"         env:"DASHBOARD_INVENTORY"         description:"Enable dashboard collector (dashboards and widget count per project and team, additional request per team, uses scrape.time.dashboard)"`
+			QueryReferences bool `long:"dashboard.query-references"  env:"DASHBOARD_QUERY_REFERENCES"  description:"Collect work item queries referenced by dashboard widgets (requires dashboard.inventory, additional request per referenced query)"`
+		}
+
+		// team settings
+		Team struct {
+			Iterations bool `long:"team.iterations"  env:"TEAM_ITERATIONS"  description:"Enable team collector (current iteration dates per team, uses scrape.time.team)"`
+		}
+
+		// query inventory settings
+		QueryInventory struct {
+			Enabled bool `long:"query.inventory"  env:"QUERY_INVENTORY"  description:"Enable query inventory collector (number of shared and personal saved queries per project, uses scrape.time.queryinventory)"`
+		}
+
+		// extension settings
+		Extension struct {
+			Inventory bool `long:"extension.inventory"  env:"EXTENSION_INVENTORY"  description:"Enable extension collector (installed extensions of the organization, requires extension management read scope, uses scrape.time.extension)"`
+		}
+
+		// variable group settings
+		VariableGroup struct {
+			Secrets bool `long:"variablegroup.secrets"  env:"VARIABLEGROUP_SECRETS"  description:"Enable variable group collector (KeyVault usage and inline secret count, uses scrape.time.projects)"`
+		}
+
+		// sharding settings
+		Sharding struct {
+			ShardIndex int `long:"sharding.index"  env:"SHARDING_INDEX"  description:"Index of this shard (0 to sharding.total-1), projects are assigned by hash of project ID"  default:"0"`
+			ShardTotal int `long:"sharding.total"  env:"SHARDING_TOTAL"  description:"Total number of shards (replicas) projects are distributed across (only project based metrics)"  default:"1"`
 		}
 
 		// cache settings
 		Cache struct {
 			Expiry time.Duration `long:"cache.expiry"  env:"CACHE_EXPIRY"  description:"Internal cache expiry time (time.duration)"  default:"30m"`
+			TTL    []string      `long:"cache.ttl"     env:"CACHE_TTL"     env-delim:" "  description:"API response cache TTL per category in the form '<category>=<time.duration>' (categories: projects, repositories, builddefinitions, releasedefinitions)"`
 		}
 
 		Request struct {
 			ConcurrencyLimit int64 `long:"request.concurrency"                   env:"REQUEST_CONCURRENCY"     description:"Number of concurrent requests against dev.azure.com"  default:"10"`
 			Retries          int   `long:"request.retries"                       env:"REQUEST_RETRIES"         description:"Number of retried requests against dev.azure.com"     default:"3"`
+
+			RetryStatusCodes []int `long:"request.retry-status-codes"  env:"REQUEST_RETRY_STATUS_CODES"  env-delim:" "  description:"HTTP status codes of responses which are retried (eg. 408, 429, 503), connection errors are always retried"`
+
+			Timeout      time.Duration `long:"request.timeout"        env:"REQUEST_TIMEOUT"        description:"Timeout (time.Duration) for requests against dev.azure.com (0 = no timeout)"  default:"0"`
+			TimeoutQuery time.Duration `long:"request.timeout.query"  env:"REQUEST_TIMEOUT_QUERY"  description:"Timeout (time.Duration) for slow requests (WIQL, workitems, build history and timelines) against dev.azure.com (0 = no timeout)"  default:"0"`
+
+			UnavailableThreshold int64         `long:"request.unavailable.threshold"  env:"REQUEST_UNAVAILABLE_THRESHOLD"  description:"Number of consecutive 503 responses after which collection is paused (0 = disabled)"  default:"0"`
+			UnavailableBackoff   time.Duration `long:"request.unavailable.backoff"    env:"REQUEST_UNAVAILABLE_BACKOFF"    description:"Time (time.Duration) collection is paused after the last 503 response"                 default:"5m"`
 		}
 
 		Limit struct {
@@ -73,15 +188,41 @@ type (
 			ReleasesPerDefinition        int64         `long:"limit.releases-per-definition"         env:"LIMIT_RELEASES_PER_DEFINITION"         description:"Limit releases per definition"    default:"100"`
 			DeploymentPerDefinition      int64         `long:"limit.deployments-per-definition"      env:"LIMIT_DEPLOYMENTS_PER_DEFINITION"      description:"Limit deployments per definition" default:"100"`
 			ReleaseDefinitionsPerProject int64         `long:"limit.releasedefinitions-per-project"  env:"LIMIT_RELEASEDEFINITION_PER_PROJECT"   description:"Limit builds per definition"      default:"100"`
+			CommitsPerRepository         int64         `long:"limit.commits-per-repository"          env:"LIMIT_COMMITS_PER_REPOSITORY"          description:"Limit commits per repository"     default:"1000"`
+			PullRequestsPerRepository    int64         `long:"limit.pullrequests-per-repository"     env:"LIMIT_PULLREQUESTS_PER_REPOSITORY"     description:"Limit completed pullrequests per repository"  default:"100"`
+			ApprovalsPerProject          int64         `long:"limit.approvals-per-project"           env:"LIMIT_APPROVALS_PER_PROJECT"           description:"Limit pipeline and release approvals per project"  default:"100"`
+			BranchesPerRepository        int           `long:"limit.branches-per-repository"         env:"LIMIT_BRANCHES_PER_REPOSITORY"         description:"Limit branches (most recent builds) per repository for branch build status"  default:"10"`
 			BuildHistoryDuration         time.Duration `long:"limit.build-history-duration"          env:"LIMIT_BUILD_HISTORY_DURATION"          description:"Time (time.Duration) how long the exporter should look back for builds"      default:"48h"`
 			ReleaseHistoryDuration       time.Duration `long:"limit.release-history-duration"        env:"LIMIT_RELEASE_HISTORY_DURATION"        description:"Time (time.Duration) how long the exporter should look back for releases"      default:"48h"`
+			PullRequestHistoryDuration   time.Duration `long:"limit.pullrequest-history-duration"    env:"LIMIT_PULLREQUEST_HISTORY_DURATION"    description:"Time (time.Duration) how long the exporter should look back for completed pullrequests"      default:"168h"`
+			BuildResultDuration          time.Duration `long:"limit.build-result-duration"           env:"LIMIT_BUILD_RESULT_DURATION"           description:"Time (time.Duration) window for counting build results (limited by limit.build-history-duration and limit.builds-per-project)"      default:"24h"`
+		}
+
+		// metrics settings
+		Metrics struct {
+			DisableRuntime   bool     `long:"metrics.disable-runtime"     env:"METRICS_DISABLE_RUNTIME"     description:"Disable Go runtime and process metrics"`
+			LabelFirstValues []string `long:"metrics.label.first-values"  env:"METRICS_LABEL_FIRST_VALUES"  env-delim:" "  description:"Keep only the first <limit> distinct values per label and project in API response order, later values are reported as '__other__' (format: '<label>=<limit>')"`
+		}
+
+		Identity struct {
+			LabelField string `long:"identity.label-field"  env:"IDENTITY_LABEL_FIELD"  description:"Identity field used for identity labels (eg. requestedBy): displayName, uniqueName, descriptor or id (falls back to display name if empty)"  default:"displayName"`
+
+			Exclude []string `long:"identity.exclude"  env:"IDENTITY_EXCLUDE"  env-delim:" "  description:"Identities (display name, unique name, email or id) not reported in identity labels (eg. requestedBy) and contributor counts"`
+			Relabel []string `long:"identity.relabel"  env:"IDENTITY_RELABEL"  env-delim:" "  description:"Report identities with another label value (eg. service accounts as 'automation') in identity labels and contributor counts (format: '<identity>=<label>')"`
 		}
 
 		Server struct {
 			// general options
 			Bind         string        `long:"server.bind"              env:"SERVER_BIND"           description:"Server address"        default:":8080"`
+			HealthBind   string        `long:"server.bind.health"       env:"SERVER_BIND_HEALTH"    description:"Server address for health endpoints (empty to serve them on server.bind)"`
 			ReadTimeout  time.Duration `long:"server.timeout.read"      env:"SERVER_TIMEOUT_READ"   description:"Server read timeout"   default:"5s"`
 			WriteTimeout time.Duration `long:"server.timeout.write"     env:"SERVER_TIMEOUT_WRITE"  description:"Server write timeout"  default:"10s"`
+			AccessLog    bool          `long:"server.log.access"        env:"SERVER_LOG_ACCESS"     description:"Log each http request (access log)"`
+
+			MetricsMaxRequestsInFlight int           `long:"server.metrics.max-inflight"  env:"SERVER_METRICS_MAX_INFLIGHT"  description:"Maximum number of concurrent /metrics requests, excess requests are rejected with 503 (0 = unlimited)"  default:"0"`
+			MetricsTimeout             time.Duration `long:"server.metrics.timeout"                 env:"SERVER_METRICS_TIMEOUT"                 description:"Timeout for /metrics requests, slower requests are answered with 503 (0 = no timeout)"  default:"0"`
+
+			HealthAuthThreshold int64 `long:"server.health.auth-threshold"  env:"SERVER_HEALTH_AUTH_THRESHOLD"  description:"Number of consecutive authentication failures (401) after which /healthz reports unhealthy, failures of project access tokens are only reported (0 = disabled)"  default:"0"`
 		}
 	}
 )
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// initConfigInfoMetric exposes the resolved (non secret) configuration as metric
+// to detect configuration drift between exporter instances
+func initConfigInfoMetric() {
+	configInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_config_info",
+			Help: "Azure DevOps exporter effective configuration",
+		},
+		[]string{
+			"name",
+			"value",
+		},
+	)
+	prometheus.MustRegister(configInfo)
+
+	configList := map[string]string{
+		"azuredevops.organisation":             opts.AzureDevops.Organisation,
+		"azuredevops.apiversion":               opts.AzureDevops.ApiVersion,
+		"request.concurrency":                  strconv.FormatInt(opts.Request.ConcurrencyLimit, 10),
+		"request.retries":                      strconv.Itoa(opts.Request.Retries),
+		"request.timeout":                      opts.Request.Timeout.String(),
+		"request.timeout.query":                opts.Request.TimeoutQuery.String(),
+		"limit.project":                        int64ToString(opts.Limit.Project),
+		"limit.builds-per-project":             int64ToString(opts.Limit.BuildsPerProject),
+		"limit.builds-per-definition":          int64ToString(opts.Limit.BuildsPerDefinition),
+		"limit.releases-per-project":           int64ToString(opts.Limit.ReleasesPerProject),
+		"limit.releases-per-definition":        int64ToString(opts.Limit.ReleasesPerDefinition),
+		"limit.deployments-per-definition":     int64ToString(opts.Limit.DeploymentPerDefinition),
+		"limit.releasedefinitions-per-project": int64ToString(opts.Limit.ReleaseDefinitionsPerProject),
+		"limit.commits-per-repository":         int64ToString(opts.Limit.CommitsPerRepository),
+		"limit.pullrequests-per-repository":    int64ToString(opts.Limit.PullRequestsPerRepository),
+		"limit.approvals-per-project":          int64ToString(opts.Limit.ApprovalsPerProject),
+		"limit.build-history-duration":         opts.Limit.BuildHistoryDuration.String(),
+		"limit.release-history-duration":       opts.Limit.ReleaseHistoryDuration.String(),
+		"sharding.index":                       strconv.Itoa(opts.Sharding.ShardIndex),
+		"sharding.total":                       strconv.Itoa(opts.Sharding.ShardTotal),
+		"cache.expiry":                         opts.Cache.Expiry.String(),
+		"identity.label-field":                 opts.Identity.LabelField,
+	}
+
+	// enabled collectors with their scrape time
+	for name, collector := range collectorGeneralList {
+		configList[fmt.Sprintf("collector.%v", name)] = collector.GetScrapeTime().String()
+	}
+	for name, collector := range collectorProjectList {
+		configList[fmt.Sprintf("collector.%v", name)] = collector.GetScrapeTime().String()
+	}
+	for name, collector := range collectorAgentPoolList {
+		configList[fmt.Sprintf("collector.%v", name)] = collector.GetScrapeTime().String()
+	}
+	for name, collector := range collectorQueryList {
+		configList[fmt.Sprintf("collector.%v", name)] = collector.GetScrapeTime().String()
+	}
+
+	for name, value := range configList {
+		configInfo.With(prometheus.Labels{
+			"name":  name,
+			"value": value,
+		}).Set(1)
+	}
+}
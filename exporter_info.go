@@ -0,0 +1,46 @@
+package main
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// process start time (package initialization)
+	exporterStartTime = time.Now()
+)
+
+// initExporterInfoMetric exposes version and start time of the exporter
+// to calculate the uptime and detect restarts
+func initExporterInfoMetric() {
+	exporterInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_exporter_info",
+			Help: "Azure DevOps exporter version information",
+		},
+		[]string{
+			"version",
+			"commit",
+			"goVersion",
+		},
+	)
+	prometheus.MustRegister(exporterInfo)
+
+	exporterStartTimestamp := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_exporter_start_timestamp_seconds",
+			Help: "Azure DevOps exporter start time of the process",
+		},
+	)
+	prometheus.MustRegister(exporterStartTimestamp)
+
+	exporterInfo.With(prometheus.Labels{
+		"version":   gitTag,
+		"commit":    gitCommit,
+		"goVersion": runtime.Version(),
+	}).Set(1)
+
+	exporterStartTimestamp.Set(float64(exporterStartTime.Unix()))
+}
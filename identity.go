@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+var (
+	// identity field used for identity labels (see setIdentityLabelField)
+	identityLabelField = "displayname"
+
+	// identities (lowercase display name, unique name, email or id) which are not reported in identity labels
+	identityExcludeList = map[string]bool{}
+
+	// identities (lowercase display name, unique name, email or id) which are reported with another label value (eg. 'automation')
+	identityRelabelList = map[string]string{}
+)
+
+func setIdentityLabelField(field string) error {
+	switch strings.ToLower(field) {
+	case "displayname", "uniquename", "descriptor", "id":
+		identityLabelField = strings.ToLower(field)
+		return nil
+	default:
+		return fmt.Errorf("identity label field '%v' is invalid; should be displayName, uniqueName, descriptor or id", field)
+	}
+}
+
+func parseIdentityRelabel(val string) (string, string, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("identity relabel '%v' is malformed; should be '<identity>=<label>'", val)
+	}
+
+	return strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), nil
+}
+
+// identityMapping returns the relabeled value and if the identity is excluded for any of the identity keys
+func identityMapping(keys ...string) (label string, excluded bool) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		key = strings.ToLower(key)
+		if identityExcludeList[key] {
+			return "", true
+		}
+
+		if val, exists := identityRelabelList[key]; exists {
+			return val, false
+		}
+	}
+
+	return "", false
+}
+
+// identityIdLabel returns the id of an identity (empty for excluded identities)
+func identityIdLabel(identity devopsClient.IdentifyRef) string {
+	if _, excluded := identityMapping(identity.DisplayName, identity.UniqueName, identity.Id); excluded {
+		return ""
+	}
+
+	return identity.Id
+}
+
+// identityLabel returns the label value for an identity (empty for excluded identities)
+func identityLabel(identity devopsClient.IdentifyRef) string {
+	label, excluded := identityMapping(identity.DisplayName, identity.UniqueName, identity.Id)
+	if excluded {
+		return ""
+	}
+
+	if label != "" {
+		return label
+	}
+
+	switch identityLabelField {
+	case "uniquename":
+		label = identity.UniqueName
+	case "descriptor":
+		label = identity.Descriptor
+	case "id":
+		label = identity.Id
+	}
+
+	if label == "" {
+		label = identity.DisplayName
+	}
+
+	return label
+}
+
+// identityListLabel returns the comma separated label values of the identities (excluded identities are skipped)
+func identityListLabel(identityList []devopsClient.IdentifyRef) string {
+	var labelList []string
+	for _, identity := range identityList {
+		if label := identityLabel(identity); label != "" {
+			labelList = append(labelList, label)
+		}
+	}
+
+	return strings.Join(labelList, ",")
+}
+
+// authorIdentity returns the identity of a commit author and false if the author is excluded
+func authorIdentity(author devopsClient.Author) (string, bool) {
+	label, excluded := identityMapping(author.Name, author.Email)
+	if excluded {
+		return "", false
+	}
+
+	if label != "" {
+		return label, true
+	}
+
+	return author.Identity(), true
+}
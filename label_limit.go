@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// label value used for all values exceeding the label limit
+	labelValueOther = "__other__"
+)
+
+type (
+	// labelValueLimiter keeps the first distinct values per label name (metrics.label.first-values),
+	// later values are reported as '__other__'.
+	// Values are kept in order of the API responses (not by frequency),
+	// so the kept values can change between collections
+	labelValueLimiter struct {
+		values map[string]map[string]bool
+	}
+)
+
+// label limits (label name -> max number of distinct values)
+var labelValueLimitList = map[string]int{}
+
+func parseLabelValueLimit(val string) (string, int, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", 0, fmt.Errorf("label limit '%v' is malformed; should be '<label>=<limit>'", val)
+	}
+
+	labelName := strings.TrimSpace(parts[0])
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || limit <= 0 {
+		return "", 0, fmt.Errorf("label limit '%v' is invalid; limit must be a positive number", val)
+	}
+
+	return labelName, limit, nil
+}
+
+func newLabelValueLimiter() *labelValueLimiter {
+	return &labelValueLimiter{
+		values: map[string]map[string]bool{},
+	}
+}
+
+// Value returns the label value or '__other__' if the limit for this label is reached
+func (l *labelValueLimiter) Value(labelName, value string) string {
+	limit, exists := labelValueLimitList[labelName]
+	if !exists {
+		return value
+	}
+
+	if _, exists := l.values[labelName]; !exists {
+		l.values[labelName] = map[string]bool{}
+	}
+
+	if l.values[labelName][value] {
+		return value
+	}
+
+	if len(l.values[labelName]) >= limit {
+		return labelValueOther
+	}
+
+	l.values[labelName][value] = true
+	return value
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// logSampler logs repeated identical messages of a collector only once per interval,
+	// suppressed messages are reported as summary with the number of repetitions
+	logSampler struct {
+		lock     sync.Mutex
+		interval time.Duration
+		entries  map[string]*logSamplerEntry
+	}
+
+	logSamplerEntry struct {
+		logger     *log.Entry
+		level      log.Level
+		message    string
+		lastLogged time.Time
+		suppressed int64
+	}
+)
+
+var errorLogSampler = &logSampler{
+	entries: map[string]*logSamplerEntry{},
+}
+
+// initLogSampling enables log sampling and starts the periodic summary of suppressed messages
+func initLogSampling(interval time.Duration) {
+	if interval.Seconds() <= 0 {
+		return
+	}
+
+	errorLogSampler.interval = interval
+	go func() {
+		for range time.Tick(interval) {
+			errorLogSampler.flush()
+		}
+	}()
+}
+
+// logError logs the error of a collector (sampled if log sampling is enabled) and marks the collection as failed
+func logError(ctx context.Context, logger *log.Entry, err error) {
+	collectionFailed(ctx)
+	errorLogSampler.log(logger, log.ErrorLevel, err.Error())
+}
+
+// logWarn logs the error of a collector as warning (sampled if log sampling is enabled)
+func logWarn(logger *log.Entry, err error) {
+	errorLogSampler.log(logger, log.WarnLevel, err.Error())
+}
+
+func (s *logSampler) log(logger *log.Entry, level log.Level, message string) {
+	if s.interval.Seconds() <= 0 {
+		logger.Log(level, message)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// identical messages are deduplicated per collector
+	key := fmt.Sprintf("%v:%v:%v", logger.Data["collector"], level, message)
+
+	entry, exists := s.entries[key]
+	if !exists {
+		s.entries[key] = &logSamplerEntry{
+			logger:     logger,
+			level:      level,
+			message:    message,
+			lastLogged: time.Now(),
+		}
+		logger.Log(level, message)
+		return
+	}
+
+	entry.logger = logger
+	if time.Since(entry.lastLogged) < s.interval {
+		entry.suppressed++
+		return
+	}
+
+	entry.summary(s.interval)
+	entry.lastLogged = time.Now()
+	logger.Log(level, message)
+}
+
+// flush logs the summary of suppressed messages and removes messages which were not repeated
+func (s *logSampler) flush() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for key, entry := range s.entries {
+		if time.Since(entry.lastLogged) < s.interval {
+			continue
+		}
+
+		if entry.suppressed == 0 {
+			delete(s.entries, key)
+			continue
+		}
+
+		entry.summary(s.interval)
+		entry.lastLogged = time.Now()
+	}
+}
+
+func (e *logSamplerEntry) summary(interval time.Duration) {
+	if e.suppressed == 0 {
+		return
+	}
+
+	e.logger.WithField("suppressed", e.suppressed).Logf(e.level, "%v (repeated %v times in the last %v)", e.message, e.suppressed, interval.String())
+	e.suppressed = 0
+}
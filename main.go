@@ -6,10 +6,15 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 
@@ -33,6 +38,8 @@ var (
 	collectorAgentPoolList map[string]*CollectorAgentPool
 	collectorQueryList     map[string]*CollectorQuery
 
+	queryList []*querySpec
+
 	// Git version information
 	gitCommit = "<unknown>"
 	gitTag    = "<unknown>"
@@ -48,9 +55,12 @@ func main() {
 	initAzureDevOpsConnection()
 	AzureDevopsServiceDiscovery = NewAzureDevopsServiceDiscovery()
 	AzureDevopsServiceDiscovery.Update()
+	validateProjectAccessTokens()
 
 	log.Info("init metrics collection")
 	initMetricCollector()
+	initConfigInfoMetric()
+	initExporterInfoMetric()
 
 	log.Infof("starting http server on %s", opts.Server.Bind)
 	startHttpServer()
@@ -104,6 +114,9 @@ func initArgparser() {
 		})
 	}
 
+	// log sampling
+	initLogSampling(opts.Logger.SampleInterval)
+
 	// load accesstoken from file
 	if opts.AzureDevops.AccessTokenFile != nil && len(*opts.AzureDevops.AccessTokenFile) > 0 {
 		log.Infof("reading access token from file \"%s\"", *opts.AzureDevops.AccessTokenFile)
@@ -119,20 +132,70 @@ func initArgparser() {
 		log.Panicf("no Azure DevOps access token specified")
 	}
 
-	// ensure query paths and projects are splitted by '@'
+	// ensure query paths and projects are splitted by '@' and options are valid
 	if opts.AzureDevops.QueriesWithProjects != nil {
 		queryError := false
 		for _, query := range opts.AzureDevops.QueriesWithProjects {
-			if strings.Count(query, "@") != 1 {
-				fmt.Println("Query path '", query, "' is malformed; should be '<query UUID>@<project UUID>'")
+			spec, err := parseQuerySpec(query)
+			if err != nil {
+				fmt.Println(err)
 				queryError = true
+				continue
 			}
+			queryList = append(queryList, spec)
 		}
 		if queryError {
 			os.Exit(1)
 		}
 	}
 
+	// parse label value limits (first values)
+	for _, val := range opts.Metrics.LabelFirstValues {
+		labelName, limit, err := parseLabelValueLimit(val)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		labelValueLimitList[labelName] = limit
+	}
+
+	if err := setIdentityLabelField(opts.Identity.LabelField); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// parse identity exclude and relabel list
+	for _, val := range opts.Identity.Exclude {
+		identityExcludeList[strings.ToLower(strings.TrimSpace(val))] = true
+	}
+	for _, val := range opts.Identity.Relabel {
+		identity, label, err := parseIdentityRelabel(val)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		identityRelabelList[identity] = label
+	}
+
+	// compile production environment matcher
+	if opts.Deployment.ProdEnvironment != "" {
+		prodEnvironmentRegexp, err := regexp.Compile(opts.Deployment.ProdEnvironment)
+		if err != nil {
+			fmt.Printf("deployment.prod-environment '%v' is invalid: %v\n", opts.Deployment.ProdEnvironment, err)
+			os.Exit(1)
+		}
+		deploymentProdEnvironmentRegexp = prodEnvironmentRegexp
+	}
+
+	if opts.Limit.BuildResultDuration > opts.Limit.BuildHistoryDuration {
+		log.Warnf("limit.build-result-duration (%v) is greater than limit.build-history-duration (%v), build results are only counted within build history", opts.Limit.BuildResultDuration.String(), opts.Limit.BuildHistoryDuration.String())
+	}
+
+	if opts.Sharding.ShardTotal < 1 || opts.Sharding.ShardIndex < 0 || opts.Sharding.ShardIndex >= opts.Sharding.ShardTotal {
+		fmt.Printf("invalid sharding: sharding.index (%v) must be between 0 and sharding.total-1 (%v)\n", opts.Sharding.ShardIndex, opts.Sharding.ShardTotal-1)
+		os.Exit(1)
+	}
+
 	// use default scrape time if null
 	if opts.Scrape.TimeProjects == nil {
 		opts.Scrape.TimeProjects = &opts.Scrape.Time
@@ -174,6 +237,26 @@ func initArgparser() {
 		opts.Scrape.TimeQuery = &opts.Scrape.Time
 	}
 
+	if opts.Scrape.TimeApproval == nil {
+		opts.Scrape.TimeApproval = &opts.Scrape.Time
+	}
+
+	if opts.Scrape.TimeDashboard == nil {
+		opts.Scrape.TimeDashboard = &opts.Scrape.Time
+	}
+
+	if opts.Scrape.TimeTeam == nil {
+		opts.Scrape.TimeTeam = &opts.Scrape.Time
+	}
+
+	if opts.Scrape.TimeQueryInventory == nil {
+		opts.Scrape.TimeQueryInventory = &opts.Scrape.Time
+	}
+
+	if opts.Scrape.TimeExtension == nil {
+		opts.Scrape.TimeExtension = &opts.Scrape.Time
+	}
+
 	if v := os.Getenv("AZURE_DEVOPS_FILTER_AGENTPOOL"); v != "" {
 		log.Panic("deprecated env var AZURE_DEVOPS_FILTER_AGENTPOOL detected, please use AZURE_DEVOPS_AGENTPOOL")
 	}
@@ -189,15 +272,51 @@ func initAzureDevOpsConnection() {
 	log.Infof("using organization: %v", opts.AzureDevops.Organisation)
 	log.Infof("using apiversion: %v", opts.AzureDevops.ApiVersion)
 	log.Infof("using concurrency: %v", opts.Request.ConcurrencyLimit)
-	log.Infof("using retries: %v", opts.Request.Retries)
+	log.Infof("using retries: %v (status codes: %v)", opts.Request.Retries, opts.Request.RetryStatusCodes)
+	log.Infof("using timeout: %v (query: %v)", opts.Request.Timeout.String(), opts.Request.TimeoutQuery.String())
 
 	AzureDevopsClient.SetOrganization(opts.AzureDevops.Organisation)
 	AzureDevopsClient.SetAccessToken(opts.AzureDevops.AccessToken)
+	AzureDevopsClient.SetAuthUsername(opts.AzureDevops.AuthUsername)
 	AzureDevopsClient.SetApiVersion(opts.AzureDevops.ApiVersion)
 	AzureDevopsClient.SetConcurrency(opts.Request.ConcurrencyLimit)
 	AzureDevopsClient.SetRetries(opts.Request.Retries)
+	AzureDevopsClient.SetRetryStatusCodes(opts.Request.RetryStatusCodes)
+	AzureDevopsClient.SetTimeout(opts.Request.Timeout)
+	AzureDevopsClient.SetTimeoutQuery(opts.Request.TimeoutQuery)
+	AzureDevopsClient.ServiceUnavailableThreshold = opts.Request.UnavailableThreshold
+	AzureDevopsClient.ServiceUnavailableBackoff = opts.Request.UnavailableBackoff
+	AzureDevopsClient.AuthFailureThreshold = opts.Server.HealthAuthThreshold
 	AzureDevopsClient.SetUserAgent(fmt.Sprintf("azure-devops-exporter/%v", gitTag))
 
+	for i, val := range opts.AzureDevops.ProjectAccessTokens {
+		parts := strings.SplitN(val, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			// value is not logged as it might contain the token
+			log.Panicf("project access token #%v is malformed; should be '<projectId>=<token>'", i+1)
+		}
+
+		AzureDevopsClient.SetProjectAccessToken(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		log.Infof("using project access token for %v", strings.TrimSpace(parts[0]))
+	}
+
+	for _, val := range opts.Cache.TTL {
+		parts := strings.SplitN(val, "=", 2)
+		if len(parts) != 2 {
+			log.Panicf("cache ttl '%v' is malformed; should be '<category>=<time.duration>'", val)
+		}
+
+		ttl, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			log.Panicf("cache ttl '%v' is invalid: %v", val, err)
+		}
+
+		if err := AzureDevopsClient.SetCacheTTL(strings.TrimSpace(parts[0]), ttl); err != nil {
+			log.Panic(err)
+		}
+		log.Infof("using api cache ttl %v for %v", ttl.String(), parts[0])
+	}
+
 	AzureDevopsClient.LimitProject = opts.Limit.Project
 	AzureDevopsClient.LimitBuildsPerProject = opts.Limit.BuildsPerProject
 	AzureDevopsClient.LimitBuildsPerDefinition = opts.Limit.BuildsPerDefinition
@@ -205,6 +324,9 @@ func initAzureDevOpsConnection() {
 	AzureDevopsClient.LimitDeploymentPerDefinition = opts.Limit.DeploymentPerDefinition
 	AzureDevopsClient.LimitReleaseDefinitionsPerProject = opts.Limit.ReleaseDefinitionsPerProject
 	AzureDevopsClient.LimitReleasesPerProject = opts.Limit.ReleasesPerProject
+	AzureDevopsClient.LimitPullRequestsPerRepository = opts.Limit.PullRequestsPerRepository
+	AzureDevopsClient.LimitApprovalsPerProject = opts.Limit.ApprovalsPerProject
+	AzureDevopsClient.ReleaseDefinitionPathFilter = opts.AzureDevops.ReleaseDefinitionPathFilter
 }
 func initMetricCollector() {
 	var collectorName string
@@ -213,6 +335,15 @@ func initMetricCollector() {
 	collectorAgentPoolList = map[string]*CollectorAgentPool{}
 	collectorQueryList = map[string]*CollectorQuery{}
 
+	initCollectorMetrics()
+
+	// go runtime and process metrics are registered by the default registry
+	if opts.Metrics.DisableRuntime {
+		log.Info("disabling go runtime and process metrics")
+		prometheus.Unregister(collectors.NewGoCollector())
+		prometheus.Unregister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+
 	collectorName = "General"
 	if opts.Scrape.TimeLive.Seconds() > 0 {
 		collectorGeneralList[collectorName] = NewCollectorGeneral(collectorName, &MetricsCollectorGeneral{})
@@ -294,6 +425,62 @@ func initMetricCollector() {
 		log.Infof("collector[%s]: disabled", collectorName)
 	}
 
+	collectorName = "Retention"
+	if opts.Project.Retention && opts.Scrape.TimeProjects.Seconds() > 0 {
+		collectorProjectList[collectorName] = NewCollectorProject(collectorName, &MetricsCollectorRetention{})
+		collectorProjectList[collectorName].SetScrapeTime(*opts.Scrape.TimeProjects)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
+	collectorName = "VariableGroup"
+	if opts.VariableGroup.Secrets && opts.Scrape.TimeProjects.Seconds() > 0 {
+		collectorProjectList[collectorName] = NewCollectorProject(collectorName, &MetricsCollectorVariableGroup{})
+		collectorProjectList[collectorName].SetScrapeTime(*opts.Scrape.TimeProjects)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
+	collectorName = "PipelineApproval"
+	if opts.Pipeline.Approvals && opts.Scrape.TimeApproval.Seconds() > 0 {
+		collectorProjectList[collectorName] = NewCollectorProject(collectorName, &MetricsCollectorPipelineApproval{})
+		collectorProjectList[collectorName].SetScrapeTime(*opts.Scrape.TimeApproval)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
+	collectorName = "Dashboard"
+	if opts.Dashboard.Inventory && opts.Scrape.TimeDashboard.Seconds() > 0 {
+		collectorProjectList[collectorName] = NewCollectorProject(collectorName, &MetricsCollectorDashboard{})
+		collectorProjectList[collectorName].SetScrapeTime(*opts.Scrape.TimeDashboard)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
+	collectorName = "Team"
+	if opts.Team.Iterations && opts.Scrape.TimeTeam.Seconds() > 0 {
+		collectorProjectList[collectorName] = NewCollectorProject(collectorName, &MetricsCollectorTeam{})
+		collectorProjectList[collectorName].SetScrapeTime(*opts.Scrape.TimeTeam)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
+	collectorName = "QueryInventory"
+	if opts.QueryInventory.Enabled && opts.Scrape.TimeQueryInventory.Seconds() > 0 {
+		collectorProjectList[collectorName] = NewCollectorProject(collectorName, &MetricsCollectorQueryInventory{})
+		collectorProjectList[collectorName].SetScrapeTime(*opts.Scrape.TimeQueryInventory)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
+	collectorName = "Extension"
+	if opts.Extension.Inventory && opts.Scrape.TimeExtension.Seconds() > 0 {
+		collectorGeneralList[collectorName] = NewCollectorGeneral(collectorName, &MetricsCollectorExtension{})
+		collectorGeneralList[collectorName].SetScrapeTime(*opts.Scrape.TimeExtension)
+	} else {
+		log.Infof("collector[%s]: disabled", collectorName)
+	}
+
 	collectorName = "ResourceUsage"
 	if opts.Scrape.TimeResourceUsage.Seconds() > 0 {
 		collectorGeneralList[collectorName] = NewCollectorGeneral(collectorName, &MetricsCollectorResourceUsage{})
@@ -305,12 +492,17 @@ func initMetricCollector() {
 	collectorName = "Query"
 	if opts.Scrape.TimeQuery.Seconds() > 0 {
 		collectorQueryList[collectorName] = NewCollectorQuery(collectorName, &MetricsCollectorQuery{})
-		collectorQueryList[collectorName].QueryList = opts.AzureDevops.QueriesWithProjects
+		collectorQueryList[collectorName].QueryList = queryList
 		collectorQueryList[collectorName].SetScrapeTime(*opts.Scrape.TimeQuery)
 	} else {
 		log.Infof("collector[%s]: disabled", collectorName)
 	}
 
+	// query schedulers are created by Run and are reported by the general collector
+	for _, collector := range collectorQueryList {
+		collector.Run()
+	}
+
 	for _, collector := range collectorGeneralList {
 		collector.Run()
 	}
@@ -322,9 +514,20 @@ func initMetricCollector() {
 	for _, collector := range collectorAgentPoolList {
 		collector.Run()
 	}
+}
 
-	for _, collector := range collectorQueryList {
-		collector.Run()
+// validateProjectAccessTokens warns about project access tokens of projects which are not collected
+func validateProjectAccessTokens() {
+	projectList := map[string]bool{}
+	for _, project := range AzureDevopsServiceDiscovery.ProjectList() {
+		projectList[strings.ToLower(project.Id)] = true
+	}
+
+	for _, val := range opts.AzureDevops.ProjectAccessTokens {
+		projectId := strings.TrimSpace(strings.SplitN(val, "=", 2)[0])
+		if !projectList[strings.ToLower(projectId)] {
+			log.Warnf("project access token for %v is unused, project is not collected (unknown, filtered or other shard)", projectId)
+		}
 	}
 }
 
@@ -332,27 +535,98 @@ func initMetricCollector() {
 func startHttpServer() {
 	mux := http.NewServeMux()
 
+	// health endpoints are served on a separate listener if configured
+	healthMux := mux
+	if opts.Server.HealthBind != "" {
+		healthMux = http.NewServeMux()
+	}
+
 	// healthz
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+	healthMux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !AzureDevopsClient.IsAuthValid() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if _, err := fmt.Fprint(w, "authentication failed"); err != nil {
+				log.Error(err)
+			}
+			return
+		}
+
+		// invalid project access tokens only affect their projects
+		if projects := AzureDevopsClient.ProjectAuthFailures(); len(projects) > 0 {
+			sort.Strings(projects)
+			if _, err := fmt.Fprintf(w, "Ok (project access token authentication failed: %v)", strings.Join(projects, ", ")); err != nil {
+				log.Error(err)
+			}
+			return
+		}
+
 		if _, err := fmt.Fprint(w, "Ok"); err != nil {
 			log.Error(err)
 		}
 	})
 
 	// readyz
-	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+	healthMux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
 		if _, err := fmt.Fprint(w, "Ok"); err != nil {
 			log.Error(err)
 		}
 	})
 
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", metricsHandler())
+
+	var handler, healthHandler http.Handler = mux, healthMux
+	if opts.Server.AccessLog {
+		handler = accessLogHandler(mux)
+		healthHandler = accessLogHandler(healthMux)
+	}
+
+	if opts.Server.HealthBind != "" {
+		log.Infof("starting http health server on %s", opts.Server.HealthBind)
+		healthSrv := &http.Server{
+			Addr:         opts.Server.HealthBind,
+			Handler:      healthHandler,
+			ReadTimeout:  opts.Server.ReadTimeout,
+			WriteTimeout: opts.Server.WriteTimeout,
+		}
+		go func() {
+			log.Fatal(healthSrv.ListenAndServe())
+		}()
+	}
 
 	srv := &http.Server{
 		Addr:         opts.Server.Bind,
-		Handler:      mux,
+		Handler:      handler,
 		ReadTimeout:  opts.Server.ReadTimeout,
 		WriteTimeout: opts.Server.WriteTimeout,
 	}
 	log.Fatal(srv.ListenAndServe())
 }
+
+// metricsHandler returns the prometheus handler with limited concurrent requests and timeout
+func metricsHandler() http.Handler {
+	scrapesRejected := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "azure_devops_exporter_scrapes_rejected_total",
+			Help: "Azure DevOps exporter /metrics requests rejected (503) because of server.metrics.max-inflight or server.metrics.timeout",
+		},
+	)
+	prometheus.MustRegister(scrapesRejected)
+
+	handler := promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			MaxRequestsInFlight: opts.Server.MetricsMaxRequestsInFlight,
+			Timeout:             opts.Server.MetricsTimeout,
+		}),
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseWriter := &accessLogResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		handler.ServeHTTP(responseWriter, r)
+
+		if responseWriter.statusCode == http.StatusServiceUnavailable {
+			scrapesRejected.Inc()
+		}
+	})
+}
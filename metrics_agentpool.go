@@ -21,6 +21,10 @@ type MetricsCollectorAgentPool struct {
 		agentPoolAgentStatus *prometheus.GaugeVec
 		agentPoolAgentJob    *prometheus.GaugeVec
 		agentPoolQueueLength *prometheus.GaugeVec
+		agentPoolOnlineRatio *prometheus.GaugeVec
+
+		agentCapability             *prometheus.GaugeVec
+		agentPoolUnsatisfiedDemands *prometheus.GaugeVec
 	}
 }
 
@@ -63,6 +67,18 @@ func (m *MetricsCollectorAgentPool) Setup(collector *CollectorAgentPool) {
 	)
 	prometheus.MustRegister(m.prometheus.agentPoolUsage)
 
+	m.prometheus.agentPoolOnlineRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_agentpool_online_ratio",
+			Help: "Azure DevOps ratio of online agents to all agents per agentpool",
+		},
+		[]string{
+			"agentPoolID",
+			"agentPoolName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.agentPoolOnlineRatio)
+
 	m.prometheus.agentPoolAgent = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "azure_devops_agentpool_agent_info",
@@ -121,23 +137,55 @@ func (m *MetricsCollectorAgentPool) Setup(collector *CollectorAgentPool) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.agentPoolQueueLength)
+
+	m.prometheus.agentCapability = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_agent_capability_info",
+			Help: "Azure DevOps agent capabilities",
+		},
+		[]string{
+			"agentPoolID",
+			"agentPoolAgentID",
+			"capability",
+			"type",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.agentCapability)
+
+	m.prometheus.agentPoolUnsatisfiedDemands = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_agentpool_unsatisfied_demands",
+			Help: "Azure DevOps number of queued jobs with demands not satisfied by any online agent",
+		},
+		[]string{
+			"agentPoolID",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.agentPoolUnsatisfiedDemands)
 }
 
 func (m *MetricsCollectorAgentPool) Reset() {
 	m.prometheus.agentPool.Reset()
 	m.prometheus.agentPoolSize.Reset()
+	m.prometheus.agentPoolOnlineRatio.Reset()
 	m.prometheus.agentPoolAgent.Reset()
 	m.prometheus.agentPoolAgentStatus.Reset()
 	m.prometheus.agentPoolAgentJob.Reset()
 	m.prometheus.agentPoolQueueLength.Reset()
+	m.prometheus.agentCapability.Reset()
+	m.prometheus.agentPoolUnsatisfiedDemands.Reset()
 }
 
 func (m *MetricsCollectorAgentPool) Collect(ctx context.Context, logger *log.Entry, callback chan<- func()) {
+	// agentpool names are only known from the agent queues of the projects
+	agentPoolNames := map[int64]string{}
 	for _, project := range m.CollectorReference.GetAzureProjects() {
 		contextLogger := logger.WithFields(log.Fields{
 			"project": project.Name,
 		})
-		m.collectAgentInfo(ctx, contextLogger, callback, project)
+		for agentPoolId, agentPoolName := range m.collectAgentInfo(ctx, contextLogger, callback, project) {
+			agentPoolNames[agentPoolId] = agentPoolName
+		}
 	}
 
 	for _, agentPoolId := range AzureDevopsServiceDiscovery.AgentPoolList() {
@@ -145,22 +193,26 @@ func (m *MetricsCollectorAgentPool) Collect(ctx context.Context, logger *log.Ent
 			"agentPoolId": agentPoolId,
 		})
 
-		m.collectAgentQueues(ctx, contextLogger, callback, agentPoolId)
-		m.collectAgentPoolJobs(ctx, contextLogger, callback, agentPoolId)
+		agentList := m.collectAgentQueues(ctx, contextLogger, callback, agentPoolId, agentPoolNames[agentPoolId])
+		m.collectAgentPoolJobs(ctx, contextLogger, callback, agentPoolId, agentList)
 	}
 }
 
-func (m *MetricsCollectorAgentPool) collectAgentInfo(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+func (m *MetricsCollectorAgentPool) collectAgentInfo(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) (agentPoolNames map[int64]string) {
 	list, err := AzureDevopsClient.ListAgentQueues(project.Id)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
+	agentPoolNames = map[int64]string{}
+
 	agentPoolInfoMetric := prometheusCommon.NewMetricsList()
 	agentPoolSizeMetric := prometheusCommon.NewMetricsList()
 
 	for _, agentQueue := range list.List {
+		agentPoolNames[agentQueue.Pool.Id] = agentQueue.Name
+
 		agentPoolInfoMetric.Add(prometheus.Labels{
 			"agentPoolID":   int64ToString(agentQueue.Pool.Id),
 			"agentPoolName": agentQueue.Name,
@@ -174,27 +226,37 @@ func (m *MetricsCollectorAgentPool) collectAgentInfo(ctx context.Context, logger
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(agentPoolInfoMetric, agentPoolSizeMetric)
 		agentPoolInfoMetric.GaugeSet(m.prometheus.agentPool)
 		agentPoolSizeMetric.GaugeSet(m.prometheus.agentPoolSize)
 	}
+
+	return
 }
 
-func (m *MetricsCollectorAgentPool) collectAgentQueues(ctx context.Context, logger *log.Entry, callback chan<- func(), agentPoolId int64) {
-	list, err := AzureDevopsClient.ListAgentPoolAgents(agentPoolId)
+func (m *MetricsCollectorAgentPool) collectAgentQueues(ctx context.Context, logger *log.Entry, callback chan<- func(), agentPoolId int64, agentPoolName string) []devopsClient.AgentPoolAgent {
+	list, err := AzureDevopsClient.ListAgentPoolAgents(agentPoolId, opts.AgentPool.Capabilities)
 	if err != nil {
-		logger.Error(err)
-		return
+		logError(ctx, logger, err)
+		return nil
 	}
 
 	agentPoolUsageMetric := prometheusCommon.NewMetricsList()
+	agentPoolOnlineRatioMetric := prometheusCommon.NewMetricsList()
 	agentPoolAgentMetric := prometheusCommon.NewMetricsList()
 	agentPoolAgentStatusMetric := prometheusCommon.NewMetricsList()
 	agentPoolAgentJobMetric := prometheusCommon.NewMetricsList()
+	agentCapabilityMetric := prometheusCommon.NewMetricsList()
 
 	agentPoolSize := 0
 	agentPoolUsed := 0
+	agentPoolOnline := 0
 	for _, agentPoolAgent := range list.List {
 		agentPoolSize++
+		if agentPoolAgent.Status == "online" {
+			agentPoolOnline++
+		}
+
 		infoLabels := prometheus.Labels{
 			"agentPoolID":           int64ToString(agentPoolId),
 			"agentPoolAgentID":      int64ToString(agentPoolAgent.Id),
@@ -210,6 +272,19 @@ func (m *MetricsCollectorAgentPool) collectAgentQueues(ctx context.Context, logg
 
 		agentPoolAgentMetric.Add(infoLabels, 1)
 
+		if opts.AgentPool.Capabilities {
+			for capabilityType, capabilities := range map[string]map[string]string{"system": agentPoolAgent.SystemCapabilities, "user": agentPoolAgent.UserCapabilities} {
+				for capabilityName := range capabilities {
+					agentCapabilityMetric.AddInfo(prometheus.Labels{
+						"agentPoolID":      int64ToString(agentPoolId),
+						"agentPoolAgentID": int64ToString(agentPoolAgent.Id),
+						"capability":       capabilityName,
+						"type":             capabilityType,
+					})
+				}
+			}
+		}
+
 		statusCreatedLabels := prometheus.Labels{
 			"agentPoolAgentID": int64ToString(agentPoolAgent.Id),
 			"type":             "created",
@@ -234,28 +309,46 @@ func (m *MetricsCollectorAgentPool) collectAgentQueues(ctx context.Context, logg
 		"agentPoolID": int64ToString(agentPoolId),
 	}, float64(agentPoolUsed)/float64(agentPoolSize))
 
+	if agentPoolSize > 0 {
+		agentPoolOnlineRatioMetric.Add(prometheus.Labels{
+			"agentPoolID":   int64ToString(agentPoolId),
+			"agentPoolName": agentPoolName,
+		}, float64(agentPoolOnline)/float64(agentPoolSize))
+	}
+
 	callback <- func() {
+		m.CollectorReference.countSeries(agentPoolUsageMetric, agentPoolOnlineRatioMetric, agentPoolAgentMetric, agentPoolAgentStatusMetric, agentPoolAgentJobMetric, agentCapabilityMetric)
 		agentPoolUsageMetric.GaugeSet(m.prometheus.agentPoolUsage)
+		agentPoolOnlineRatioMetric.GaugeSet(m.prometheus.agentPoolOnlineRatio)
 		agentPoolAgentMetric.GaugeSet(m.prometheus.agentPoolAgent)
 		agentPoolAgentStatusMetric.GaugeSet(m.prometheus.agentPoolAgentStatus)
 		agentPoolAgentJobMetric.GaugeSet(m.prometheus.agentPoolAgentJob)
+		agentCapabilityMetric.GaugeSet(m.prometheus.agentCapability)
 	}
+
+	return list.List
 }
 
-func (m *MetricsCollectorAgentPool) collectAgentPoolJobs(ctx context.Context, logger *log.Entry, callback chan<- func(), agentPoolId int64) {
+func (m *MetricsCollectorAgentPool) collectAgentPoolJobs(ctx context.Context, logger *log.Entry, callback chan<- func(), agentPoolId int64, agentList []devopsClient.AgentPoolAgent) {
 	list, err := AzureDevopsClient.ListAgentPoolJobs(agentPoolId)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
 	agentPoolQueueLengthMetric := prometheusCommon.NewMetricsList()
+	agentPoolUnsatisfiedDemandsMetric := prometheusCommon.NewMetricsList()
 
 	notStartedJobCount := 0
+	unsatisfiedDemandsJobCount := 0
 
 	for _, agentPoolJob := range list.List {
 		if agentPoolJob.AssignTime == nil {
 			notStartedJobCount++
+
+			if opts.AgentPool.Capabilities && agentList != nil && !agentListSatisfiesDemands(agentList, agentPoolJob.Demands) {
+				unsatisfiedDemandsJobCount++
+			}
 		}
 	}
 
@@ -265,7 +358,24 @@ func (m *MetricsCollectorAgentPool) collectAgentPoolJobs(ctx context.Context, lo
 
 	agentPoolQueueLengthMetric.Add(infoLabels, float64(notStartedJobCount))
 
+	if opts.AgentPool.Capabilities && agentList != nil {
+		agentPoolUnsatisfiedDemandsMetric.Add(infoLabels, float64(unsatisfiedDemandsJobCount))
+	}
+
 	callback <- func() {
+		m.CollectorReference.countSeries(agentPoolQueueLengthMetric, agentPoolUnsatisfiedDemandsMetric)
 		agentPoolQueueLengthMetric.GaugeSet(m.prometheus.agentPoolQueueLength)
+		agentPoolUnsatisfiedDemandsMetric.GaugeSet(m.prometheus.agentPoolUnsatisfiedDemands)
 	}
 }
+
+// agentListSatisfiesDemands checks if any enabled and online agent satisfies the demands
+func agentListSatisfiesDemands(agentList []devopsClient.AgentPoolAgent, demands []string) bool {
+	for _, agent := range agentList {
+		if agent.Enabled && agent.Status == "online" && agent.SatisfiesDemands(demands) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
+	cache "github.com/patrickmn/go-cache"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	prometheusCommon "github.com/webdevops/go-common/prometheus"
@@ -18,22 +20,42 @@ type MetricsCollectorBuild struct {
 	prometheus struct {
 		build       *prometheus.GaugeVec
 		buildStatus *prometheus.GaugeVec
+		buildResult *prometheus.GaugeVec
 
-		buildDefinition *prometheus.GaugeVec
+		branchBuildStatus   *prometheus.GaugeVec
+		hostedJobsRunning   *prometheus.GaugeVec
+		buildValidationFail *prometheus.GaugeVec
+		buildTriggerLatency *prometheus.GaugeVec
+
+		buildDefinition         *prometheus.GaugeVec
+		buildDefinitionTrigger  *prometheus.GaugeVec
+		buildDefinitionModified *prometheus.GaugeVec
+		buildDefinitionStale    *prometheus.GaugeVec
+		buildDefinitionDemand   *prometheus.GaugeVec
 
 		buildStage *prometheus.GaugeVec
 		buildPhase *prometheus.GaugeVec
 		buildJob   *prometheus.GaugeVec
 		buildTask  *prometheus.GaugeVec
 
+		pipelineStageCount *prometheus.GaugeVec
+		pipelineJobCount   *prometheus.GaugeVec
+		pipelineDependency *prometheus.GaugeVec
+
 		buildTimeProject *prometheus.SummaryVec
 		jobTimeProject   *prometheus.SummaryVec
 	}
+
+	// completed builds which published artifacts or test results (for build.with-outputs)
+	buildOutputCache *cache.Cache
 }
 
 func (m *MetricsCollectorBuild) Setup(collector *CollectorProject) {
 	m.CollectorReference = collector
 
+	// builds older than the build history are not listed anymore
+	m.buildOutputCache = cache.New(opts.Limit.BuildHistoryDuration, 1*time.Hour)
+
 	m.prometheus.build = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "azure_devops_build_info",
@@ -73,6 +95,75 @@ func (m *MetricsCollectorBuild) Setup(collector *CollectorProject) {
 	)
 	prometheus.MustRegister(m.prometheus.buildStatus)
 
+	m.prometheus.buildResult = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_result_count",
+			Help: "Azure DevOps number of finished builds by result within limit.build-result-duration (gauge over a sliding window, not a counter; limited by limit.builds-per-project)",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+			"result",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildResult)
+
+	m.prometheus.branchBuildStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_branch_build_status",
+			Help: "Azure DevOps result of latest build per branch (branches with builds within limit.build-history-duration)",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+			"branchName",
+			"buildDefinitionID",
+			"result",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.branchBuildStatus)
+
+	m.prometheus.buildValidationFail = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_validation_failure_count",
+			Help: "Azure DevOps number of builds failed by validation errors, eg. yaml syntax errors (within limit.build-result-duration)",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildValidationFail)
+
+	hostedJobsLabels := []string{
+		"projectID",
+	}
+	if opts.Build.HostedJobsPerDefinition {
+		hostedJobsLabels = append(hostedJobsLabels, "buildDefinitionID")
+	}
+	m.prometheus.hostedJobsRunning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_hosted_jobs_running",
+			Help: "Azure DevOps number of in-progress builds (including yaml deployments, excluding classic release deployments) running on hosted agent pools",
+		},
+		hostedJobsLabels,
+	)
+	prometheus.MustRegister(m.prometheus.hostedJobsRunning)
+
+	m.prometheus.buildTriggerLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_trigger_latency_seconds",
+			Help: "Azure DevOps latency from source commit to start of the latest CI triggered build",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+			"reason",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildTriggerLatency)
+
 	m.prometheus.buildStage = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "azure_devops_build_stage",
@@ -152,6 +243,46 @@ func (m *MetricsCollectorBuild) Setup(collector *CollectorProject) {
 	)
 	prometheus.MustRegister(m.prometheus.buildTask)
 
+	m.prometheus.pipelineStageCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pipeline_stage_count",
+			Help: "Azure DevOps pipeline number of stages (from timeline of latest completed build)",
+		},
+		[]string{
+			"projectID",
+			"pipelineId",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pipelineStageCount)
+
+	m.prometheus.pipelineJobCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pipeline_job_count",
+			Help: "Azure DevOps pipeline number of jobs (from timeline of latest completed build)",
+		},
+		[]string{
+			"projectID",
+			"pipelineId",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pipelineJobCount)
+
+	m.prometheus.pipelineDependency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pipeline_dependency",
+			Help: "Azure DevOps pipeline resources (pipelines and repositories) of latest pipeline run",
+		},
+		[]string{
+			"projectID",
+			"pipelineId",
+			"resourceType",
+			"resourceAlias",
+			"resourceId",
+			"resourceName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pipelineDependency)
+
 	m.prometheus.buildDefinition = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "azure_devops_build_definition_info",
@@ -167,32 +298,122 @@ func (m *MetricsCollectorBuild) Setup(collector *CollectorProject) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.buildDefinition)
+
+	m.prometheus.buildDefinitionTrigger = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_definition_trigger_enabled",
+			Help: "Azure DevOps build definition trigger enabled",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+			"triggerType",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildDefinitionTrigger)
+
+	m.prometheus.buildDefinitionModified = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_definition_modified_timestamp_seconds",
+			Help: "Azure DevOps build definition last modification (revision) timestamp",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+			"revision",
+			"authoredBy",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildDefinitionModified)
+
+	m.prometheus.buildDefinitionStale = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_definition_stale",
+			Help: "Azure DevOps enabled build definition without successful build within build.stale-duration",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+			"buildDefinitionName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildDefinitionStale)
+
+	m.prometheus.buildDefinitionDemand = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_build_definition_demand",
+			Help: "Azure DevOps agent capability demanded by build definition (value is empty if only the capability has to exist)",
+		},
+		[]string{
+			"projectID",
+			"buildDefinitionID",
+			"demand",
+			"value",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.buildDefinitionDemand)
 }
 
 func (m *MetricsCollectorBuild) Reset() {
 	m.prometheus.build.Reset()
 	m.prometheus.buildDefinition.Reset()
+	m.prometheus.buildDefinitionTrigger.Reset()
+	m.prometheus.buildDefinitionModified.Reset()
+	m.prometheus.buildDefinitionStale.Reset()
+	m.prometheus.buildDefinitionDemand.Reset()
 	m.prometheus.buildStatus.Reset()
+	m.prometheus.buildResult.Reset()
+	m.prometheus.branchBuildStatus.Reset()
+	m.prometheus.hostedJobsRunning.Reset()
+	m.prometheus.buildValidationFail.Reset()
+	m.prometheus.buildTriggerLatency.Reset()
 	m.prometheus.buildStage.Reset()
 	m.prometheus.buildPhase.Reset()
 	m.prometheus.buildJob.Reset()
 	m.prometheus.buildTask.Reset()
+	m.prometheus.pipelineStageCount.Reset()
+	m.prometheus.pipelineJobCount.Reset()
+	m.prometheus.pipelineDependency.Reset()
 }
 
 func (m *MetricsCollectorBuild) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
 	m.collectDefinition(ctx, logger, callback, project)
 	m.collectBuilds(ctx, logger, callback, project)
+	m.collectHostedJobs(ctx, logger, callback, project)
 	m.collectBuildsTimeline(ctx, logger, callback, project)
+
+	if opts.Pipeline.Dependencies {
+		m.collectPipelineDependencies(ctx, logger, callback, project)
+	}
 }
 
 func (m *MetricsCollectorBuild) collectDefinition(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
-	list, err := AzureDevopsClient.ListBuildDefinitions(project.Id)
+	list, err := AzureDevopsClient.ListBuildDefinitions(project.Id, opts.Build.DefinitionProperties)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
 	buildDefinitonMetric := prometheusCommon.NewMetricsList()
+	buildDefinitonTriggerMetric := prometheusCommon.NewMetricsList()
+	buildDefinitonModifiedMetric := prometheusCommon.NewMetricsList()
+	buildDefinitonStaleMetric := prometheusCommon.NewMetricsList()
+	buildDefinitonDemandMetric := prometheusCommon.NewMetricsList()
+	labelLimiter := newLabelValueLimiter()
+
+	// latest successful build per definition (for stale definitions)
+	var latestSuccessfulBuilds map[int64]devopsClient.Build
+	if opts.Build.StaleDuration.Seconds() > 0 {
+		if buildList, err := AzureDevopsClient.ListLatestSuccessfulBuilds(project.Id); err == nil {
+			latestSuccessfulBuilds = map[int64]devopsClient.Build{}
+			for _, build := range buildList.List {
+				latestSuccessfulBuilds[build.Definition.Id] = build
+			}
+		} else {
+			logError(ctx, logger, err)
+		}
+	}
+	staleMinTime := time.Now().Add(-opts.Build.StaleDuration)
 
 	for _, buildDefinition := range list.List {
 		buildDefinitonMetric.Add(prometheus.Labels{
@@ -203,10 +424,54 @@ func (m *MetricsCollectorBuild) collectDefinition(ctx context.Context, logger *l
 			"path":                buildDefinition.Path,
 			"url":                 buildDefinition.Links.Web.Href,
 		}, 1)
+
+		// triggers and demands are only listed with all definition properties
+		if opts.Build.DefinitionProperties {
+			for _, triggerType := range []string{"continuousIntegration", "pullRequest", "schedule"} {
+				buildDefinitonTriggerMetric.AddBool(prometheus.Labels{
+					"projectID":         project.Id,
+					"buildDefinitionID": int64ToString(buildDefinition.Id),
+					"triggerType":       triggerType,
+				}, buildDefinition.HasTrigger(triggerType))
+			}
+
+			for _, demand := range buildDefinition.Demands {
+				buildDefinitonDemandMetric.AddInfo(prometheus.Labels{
+					"projectID":         project.Id,
+					"buildDefinitionID": int64ToString(buildDefinition.Id),
+					"demand":            demand.Name,
+					"value":             demand.Value,
+				})
+			}
+		}
+
+		if !buildDefinition.CreatedDate.IsZero() {
+			buildDefinitonModifiedMetric.AddTime(prometheus.Labels{
+				"projectID":         project.Id,
+				"buildDefinitionID": int64ToString(buildDefinition.Id),
+				"revision":          int64ToString(buildDefinition.Revision),
+				"authoredBy":        labelLimiter.Value("authoredBy", identityLabel(buildDefinition.AuthoredBy)),
+			}, buildDefinition.CreatedDate)
+		}
+
+		// only enabled definitions are expected to run
+		if latestSuccessfulBuilds != nil && strings.EqualFold(buildDefinition.QueueStatus, "enabled") {
+			build, exists := latestSuccessfulBuilds[buildDefinition.Id]
+			buildDefinitonStaleMetric.AddBool(prometheus.Labels{
+				"projectID":           project.Id,
+				"buildDefinitionID":   int64ToString(buildDefinition.Id),
+				"buildDefinitionName": buildDefinition.Name,
+			}, !exists || build.FinishTime.Before(staleMinTime))
+		}
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(buildDefinitonMetric, buildDefinitonTriggerMetric, buildDefinitonModifiedMetric, buildDefinitonStaleMetric, buildDefinitonDemandMetric)
 		buildDefinitonMetric.GaugeSet(m.prometheus.buildDefinition)
+		buildDefinitonTriggerMetric.GaugeSet(m.prometheus.buildDefinitionTrigger)
+		buildDefinitonModifiedMetric.GaugeSet(m.prometheus.buildDefinitionModified)
+		buildDefinitonStaleMetric.GaugeSet(m.prometheus.buildDefinitionStale)
+		buildDefinitonDemandMetric.GaugeSet(m.prometheus.buildDefinitionDemand)
 	}
 }
 
@@ -215,14 +480,78 @@ func (m *MetricsCollectorBuild) collectBuilds(ctx context.Context, logger *log.E
 
 	list, err := AzureDevopsClient.ListBuildHistory(project.Id, minTime)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
 	buildMetric := prometheusCommon.NewMetricsList()
 	buildStatusMetric := prometheusCommon.NewMetricsList()
+	buildResultMetric := prometheusCommon.NewHashedMetricsList()
+	branchBuildStatusMetric := prometheusCommon.NewMetricsList()
+	buildValidationFailMetric := prometheusCommon.NewHashedMetricsList()
+	buildTriggerLatencyMetric := prometheusCommon.NewMetricsList()
+	labelLimiter := newLabelValueLimiter()
+
+	// latest started build per definition triggered by a source change
+	latestTriggeredBuilds := map[int64]devopsClient.Build{}
+
+	// latest finished build per repository (id), branch and definition
+	latestBranchBuilds := map[string]map[string]devopsClient.Build{}
+
+	resultMinTime := time.Now().Add(-opts.Limit.BuildResultDuration)
+
+	// build history is capped by limit.builds-per-project, result counts are truncated if the cap doesn't cover the window
+	if int64(len(list.List)) >= opts.Limit.BuildsPerProject {
+		var oldestFinishTime time.Time
+		for _, build := range list.List {
+			if !build.FinishTime.IsZero() && (oldestFinishTime.IsZero() || build.FinishTime.Before(oldestFinishTime)) {
+				oldestFinishTime = build.FinishTime
+			}
+		}
+
+		if oldestFinishTime.After(resultMinTime) {
+			logger.Warnf("build history is limited to %v builds (limit.builds-per-project), build results are only counted since %v instead of %v", opts.Limit.BuildsPerProject, oldestFinishTime.Format(time.RFC3339), resultMinTime.Format(time.RFC3339))
+		}
+	}
 
 	for _, build := range list.List {
+		if opts.Build.WithOutputs && !m.buildHasOutputs(logger, project, build) {
+			continue
+		}
+
+		if build.Result != "" && build.FinishTime.After(resultMinTime) {
+			buildResultMetric.Inc(prometheus.Labels{
+				"projectID":         project.Id,
+				"buildDefinitionID": int64ToString(build.Definition.Id),
+				"result":            build.Result,
+			})
+
+			if build.IsValidationFailure() {
+				buildValidationFailMetric.Inc(prometheus.Labels{
+					"projectID":         project.Id,
+					"buildDefinitionID": int64ToString(build.Definition.Id),
+				})
+			}
+		}
+
+		if build.Result != "" && strings.HasPrefix(build.SourceBranch, "refs/heads/") {
+			repositoryID := build.Repository.Id
+			if _, exists := latestBranchBuilds[repositoryID]; !exists {
+				latestBranchBuilds[repositoryID] = map[string]devopsClient.Build{}
+			}
+
+			branchKey := build.SourceBranch + ":" + int64ToString(build.Definition.Id)
+			if latestBuild, exists := latestBranchBuilds[repositoryID][branchKey]; !exists || build.FinishTime.After(latestBuild.FinishTime) {
+				latestBranchBuilds[repositoryID][branchKey] = build
+			}
+		}
+
+		if opts.Build.TriggerLatency && buildIsTriggered(build) && !build.StartTime.IsZero() {
+			if latestBuild, exists := latestTriggeredBuilds[build.Definition.Id]; !exists || build.StartTime.After(latestBuild.StartTime) {
+				latestTriggeredBuilds[build.Definition.Id] = build
+			}
+		}
+
 		buildMetric.AddInfo(prometheus.Labels{
 			"projectID":         project.Id,
 			"buildDefinitionID": int64ToString(build.Definition.Id),
@@ -230,7 +559,7 @@ func (m *MetricsCollectorBuild) collectBuilds(ctx context.Context, logger *log.E
 			"buildNumber":       build.BuildNumber,
 			"buildName":         build.Definition.Name,
 			"agentPoolID":       int64ToString(build.Queue.Pool.Id),
-			"requestedBy":       build.RequestedBy.DisplayName,
+			"requestedBy":       labelLimiter.Value("requestedBy", identityLabel(build.RequestedBy)),
 			"sourceBranch":      build.SourceBranch,
 			"sourceVersion":     build.SourceVersion,
 			"status":            build.Status,
@@ -285,17 +614,143 @@ func (m *MetricsCollectorBuild) collectBuilds(ctx context.Context, logger *log.E
 		}, build.FinishTime.Sub(build.StartTime))
 	}
 
+	for repositoryID, branchBuilds := range latestBranchBuilds {
+		buildList := []devopsClient.Build{}
+		for _, build := range branchBuilds {
+			buildList = append(buildList, build)
+		}
+
+		// only the most recent branches
+		sort.Slice(buildList, func(i, j int) bool {
+			return buildList[i].FinishTime.After(buildList[j].FinishTime)
+		})
+		if len(buildList) > opts.Limit.BranchesPerRepository {
+			buildList = buildList[:opts.Limit.BranchesPerRepository]
+		}
+
+		for _, build := range buildList {
+			branchBuildStatusMetric.AddInfo(prometheus.Labels{
+				"projectID":         project.Id,
+				"repositoryID":      repositoryID,
+				"repositoryName":    build.Repository.Name,
+				"branchName":        strings.TrimPrefix(build.SourceBranch, "refs/heads/"),
+				"buildDefinitionID": int64ToString(build.Definition.Id),
+				"result":            build.Result,
+			})
+		}
+	}
+
+	for _, build := range latestTriggeredBuilds {
+		commit, err := AzureDevopsClient.GetCommit(project.Id, build.Repository.Id, build.SourceVersion)
+		if err != nil {
+			logWarn(logger, err)
+			continue
+		}
+
+		if commit.Committer.Date.IsZero() {
+			continue
+		}
+
+		buildTriggerLatencyMetric.AddDuration(prometheus.Labels{
+			"projectID":         project.Id,
+			"buildDefinitionID": int64ToString(build.Definition.Id),
+			"reason":            build.Reason,
+		}, build.StartTime.Sub(commit.Committer.Date))
+	}
+
 	callback <- func() {
+		m.CollectorReference.countSeries(buildMetric, buildStatusMetric, buildResultMetric, branchBuildStatusMetric, buildValidationFailMetric, buildTriggerLatencyMetric)
 		buildMetric.GaugeSet(m.prometheus.build)
 		buildStatusMetric.GaugeSet(m.prometheus.buildStatus)
+		buildResultMetric.GaugeSet(m.prometheus.buildResult)
+		branchBuildStatusMetric.GaugeSet(m.prometheus.branchBuildStatus)
+		buildValidationFailMetric.GaugeSet(m.prometheus.buildValidationFail)
+		buildTriggerLatencyMetric.GaugeSet(m.prometheus.buildTriggerLatency)
 	}
 }
 
+// buildHasOutputs checks if a completed build published artifacts or test results, builds which are not completed yet are always collected
+func (m *MetricsCollectorBuild) buildHasOutputs(logger *log.Entry, project devopsClient.Project, build devopsClient.Build) bool {
+	if build.Result == "" {
+		return true
+	}
+
+	cacheKey := int64ToString(build.Id)
+	if val, ok := m.buildOutputCache.Get(cacheKey); ok {
+		return val.(bool)
+	}
+
+	artifactList, err := AzureDevopsClient.ListBuildArtifacts(project.Id, int64ToString(build.Id))
+	if err != nil {
+		logWarn(logger, err)
+		return true
+	}
+
+	hasOutputs := artifactList.Count > 0
+	if !hasOutputs {
+		testRunList, err := AzureDevopsClient.ListBuildTestRuns(project.Id, build.Uri)
+		if err != nil {
+			logWarn(logger, err)
+			return true
+		}
+		hasOutputs = testRunList.Count > 0
+	}
+
+	m.buildOutputCache.SetDefault(cacheKey, hasOutputs)
+	return hasOutputs
+}
+
+// collectHostedJobs counts the running builds on hosted agent pools, running builds are fetched separately
+// as the build history is filtered by finish time
+func (m *MetricsCollectorBuild) collectHostedJobs(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	list, err := AzureDevopsClient.ListRunningBuilds(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	hostedJobsRunningMetric := prometheusCommon.NewHashedMetricsList()
+
+	for _, build := range list.List {
+		if !build.Queue.Pool.IsHosted {
+			continue
+		}
+
+		hostedJobsLabels := prometheus.Labels{
+			"projectID": project.Id,
+		}
+		if opts.Build.HostedJobsPerDefinition {
+			hostedJobsLabels["buildDefinitionID"] = int64ToString(build.Definition.Id)
+		}
+		hostedJobsRunningMetric.Inc(hostedJobsLabels)
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(hostedJobsRunningMetric)
+		hostedJobsRunningMetric.GaugeSet(m.prometheus.hostedJobsRunning)
+	}
+}
+
+// buildIsTriggered checks if the build was triggered by a push to an Azure Repos git repository (CI only),
+// pullrequest builds are skipped as their sourceVersion is the generated merge commit
+func buildIsTriggered(build devopsClient.Build) bool {
+	if build.Repository.Type != "TfsGit" || build.SourceVersion == "" {
+		return false
+	}
+
+	switch build.Reason {
+	case "individualCI", "batchedCI":
+		return true
+	}
+
+	return false
+}
+
 func (m *MetricsCollectorBuild) collectBuildsTimeline(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
 	minTime := time.Now().Add(-opts.Limit.BuildHistoryDuration)
 	list, err := AzureDevopsClient.ListBuildHistoryWithStatus(project.Id, minTime, "completed")
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
@@ -303,9 +758,50 @@ func (m *MetricsCollectorBuild) collectBuildsTimeline(ctx context.Context, logge
 	buildPhaseMetric := prometheusCommon.NewMetricsList()
 	buildJobMetric := prometheusCommon.NewMetricsList()
 	buildTaskMetric := prometheusCommon.NewMetricsList()
+	pipelineStageCountMetric := prometheusCommon.NewMetricsList()
+	pipelineJobCountMetric := prometheusCommon.NewMetricsList()
+
+	if opts.Build.WithOutputs {
+		buildList := []devopsClient.Build{}
+		for _, build := range list.List {
+			if m.buildHasOutputs(logger, project, build) {
+				buildList = append(buildList, build)
+			}
+		}
+		list.List = buildList
+	}
+
+	// pipeline structure is taken from the latest completed build of each definition
+	latestBuildList := map[int64]devopsClient.Build{}
+	for _, build := range list.List {
+		if latestBuild, exists := latestBuildList[build.Definition.Id]; !exists || build.FinishTime.After(latestBuild.FinishTime) {
+			latestBuildList[build.Definition.Id] = build
+		}
+	}
 
 	for _, build := range list.List {
 		timelineRecordList, _ := AzureDevopsClient.ListBuildTimeline(project.Id, int64ToString(build.Id))
+
+		if latestBuild, exists := latestBuildList[build.Definition.Id]; exists && latestBuild.Id == build.Id {
+			stageCount := 0
+			jobCount := 0
+			for _, timelineRecord := range timelineRecordList.List {
+				switch strings.ToLower(timelineRecord.RecordType) {
+				case "stage":
+					stageCount++
+				case "job":
+					jobCount++
+				}
+			}
+
+			pipelineLabels := prometheus.Labels{
+				"projectID":  project.Id,
+				"pipelineId": int64ToString(build.Definition.Id),
+			}
+			pipelineStageCountMetric.Add(pipelineLabels, float64(stageCount))
+			pipelineJobCountMetric.Add(pipelineLabels, float64(jobCount))
+		}
+
 		for _, timelineRecord := range timelineRecordList.List {
 			recordType := timelineRecord.RecordType
 			switch strings.ToLower(recordType) {
@@ -623,9 +1119,58 @@ func (m *MetricsCollectorBuild) collectBuildsTimeline(ctx context.Context, logge
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(buildStageMetric, buildPhaseMetric, buildJobMetric, buildTaskMetric, pipelineStageCountMetric, pipelineJobCountMetric)
 		buildStageMetric.GaugeSet(m.prometheus.buildStage)
 		buildPhaseMetric.GaugeSet(m.prometheus.buildPhase)
 		buildJobMetric.GaugeSet(m.prometheus.buildJob)
 		buildTaskMetric.GaugeSet(m.prometheus.buildTask)
+		pipelineStageCountMetric.GaugeSet(m.prometheus.pipelineStageCount)
+		pipelineJobCountMetric.GaugeSet(m.prometheus.pipelineJobCount)
+	}
+}
+
+func (m *MetricsCollectorBuild) collectPipelineDependencies(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	list, err := AzureDevopsClient.ListLatestBuilds(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	pipelineDependencyMetric := prometheusCommon.NewMetricsList()
+
+	for _, build := range list.List {
+		// classic pipelines are not available in the pipelines api
+		run, err := AzureDevopsClient.GetPipelineRun(project.Id, build.Definition.Id, build.Id)
+		if err != nil {
+			logger.WithField("pipeline", build.Definition.Name).Debug(err)
+			continue
+		}
+
+		for alias, resource := range run.Resources.Pipelines {
+			pipelineDependencyMetric.AddInfo(prometheus.Labels{
+				"projectID":     project.Id,
+				"pipelineId":    int64ToString(build.Definition.Id),
+				"resourceType":  "pipeline",
+				"resourceAlias": alias,
+				"resourceId":    int64ToString(resource.Pipeline.Id),
+				"resourceName":  resource.Pipeline.Name,
+			})
+		}
+
+		for alias, resource := range run.Resources.Repositories {
+			pipelineDependencyMetric.AddInfo(prometheus.Labels{
+				"projectID":     project.Id,
+				"pipelineId":    int64ToString(build.Definition.Id),
+				"resourceType":  "repository",
+				"resourceAlias": alias,
+				"resourceId":    resource.Repository.Id,
+				"resourceName":  resource.Repository.FullName,
+			})
+		}
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(pipelineDependencyMetric)
+		pipelineDependencyMetric.GaugeSet(m.prometheus.pipelineDependency)
 	}
 }
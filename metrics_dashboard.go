@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+type MetricsCollectorDashboard struct {
+	CollectorProcessorProject
+
+	prometheus struct {
+		dashboard            *prometheus.GaugeVec
+		dashboardWidgetCount *prometheus.GaugeVec
+		teamDashboardCount   *prometheus.GaugeVec
+		dashboardQuery       *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorDashboard) Setup(collector *CollectorProject) {
+	m.CollectorReference = collector
+
+	m.prometheus.dashboard = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_dashboard_info",
+			Help: "Azure DevOps dashboard",
+		},
+		[]string{
+			"projectID",
+			"dashboardId",
+			"dashboardName",
+			"team",
+			"scope",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.dashboard)
+
+	m.prometheus.dashboardWidgetCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_dashboard_widget_count",
+			Help: "Azure DevOps number of widgets per dashboard",
+		},
+		[]string{
+			"projectID",
+			"dashboardId",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.dashboardWidgetCount)
+
+	m.prometheus.teamDashboardCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_team_dashboard_count",
+			Help: "Azure DevOps number of dashboards per team",
+		},
+		[]string{
+			"projectID",
+			"teamId",
+			"team",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.teamDashboardCount)
+
+	m.prometheus.dashboardQuery = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_dashboard_query_widget_count",
+			Help: "Azure DevOps number of dashboard widgets referencing a work item query (queryPath is empty if the query could not be found)",
+		},
+		[]string{
+			"projectID",
+			"dashboardId",
+			"queryId",
+			"queryPath",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.dashboardQuery)
+}
+
+func (m *MetricsCollectorDashboard) Reset() {
+	m.prometheus.dashboard.Reset()
+	m.prometheus.dashboardWidgetCount.Reset()
+	m.prometheus.teamDashboardCount.Reset()
+	m.prometheus.dashboardQuery.Reset()
+}
+
+func (m *MetricsCollectorDashboard) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	teamList, err := AzureDevopsClient.ListTeams(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	// project dashboards, team dashboards are only listed on the team route
+	list, err := AzureDevopsClient.ListDashboards(project.Id, "")
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	dashboardMetric := prometheusCommon.NewMetricsList()
+	dashboardWidgetCountMetric := prometheusCommon.NewMetricsList()
+	teamDashboardCountMetric := prometheusCommon.NewMetricsList()
+	dashboardQueryMetric := prometheusCommon.NewHashedMetricsList()
+
+	// query paths of referenced queries (resolved once per collection)
+	queryPathList := map[string]string{}
+
+	// dashboard id -> team (empty for project dashboards)
+	dashboardTeamList := map[string]devopsClient.Team{}
+	dashboardList := list.List
+
+	teamNameList := map[string]string{}
+	teamDashboardCount := map[string]int{}
+	for _, team := range teamList.List {
+		teamNameList[team.Id] = team.Name
+		teamDashboardCount[team.Id] = 0
+
+		teamDashboardList, err := AzureDevopsClient.ListDashboards(project.Id, team.Id)
+		if err != nil {
+			logWarn(logger.WithField("team", team.Name), err)
+			continue
+		}
+
+		for _, dashboard := range teamDashboardList.List {
+			if _, exists := dashboardTeamList[dashboard.Id]; exists {
+				continue
+			}
+
+			dashboardTeamList[dashboard.Id] = team
+			dashboardList = append(dashboardList, dashboard)
+			teamDashboardCount[team.Id]++
+		}
+	}
+
+	seenDashboards := map[string]bool{}
+	for _, dashboard := range dashboardList {
+		if seenDashboards[dashboard.Id] {
+			continue
+		}
+		seenDashboards[dashboard.Id] = true
+
+		team := dashboardTeamList[dashboard.Id]
+
+		dashboardMetric.AddInfo(prometheus.Labels{
+			"projectID":     project.Id,
+			"dashboardId":   dashboard.Id,
+			"dashboardName": dashboard.Name,
+			"team":          team.Name,
+			"scope":         dashboard.DashboardScope,
+		})
+
+		// widgets are not included in dashboard list
+		dashboardDetail, err := AzureDevopsClient.GetDashboard(project.Id, team.Id, dashboard.Id)
+		if err != nil {
+			logWarn(logger, err)
+			continue
+		}
+
+		dashboardWidgetCountMetric.Add(prometheus.Labels{
+			"projectID":   project.Id,
+			"dashboardId": dashboard.Id,
+		}, float64(len(dashboardDetail.Widgets)))
+
+		if opts.Dashboard.QueryReferences {
+			for _, widget := range dashboardDetail.Widgets {
+				for _, queryId := range widget.QueryIdList() {
+					queryPath, exists := queryPathList[queryId]
+					if !exists {
+						if query, err := AzureDevopsClient.GetQueryFolder(project.Id, queryId, 0); err == nil {
+							queryPath = query.Path
+						} else {
+							logger.Debugf("unable to resolve query %v of dashboard %v: %v", queryId, dashboard.Id, err)
+						}
+						queryPathList[queryId] = queryPath
+					}
+
+					dashboardQueryMetric.Inc(prometheus.Labels{
+						"projectID":   project.Id,
+						"dashboardId": dashboard.Id,
+						"queryId":     queryId,
+						"queryPath":   queryPath,
+					})
+				}
+			}
+		}
+	}
+
+	for teamId, count := range teamDashboardCount {
+		teamDashboardCountMetric.Add(prometheus.Labels{
+			"projectID": project.Id,
+			"teamId":    teamId,
+			"team":      teamNameList[teamId],
+		}, float64(count))
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(dashboardMetric, dashboardWidgetCountMetric, teamDashboardCountMetric, dashboardQueryMetric)
+		dashboardMetric.GaugeSet(m.prometheus.dashboard)
+		dashboardWidgetCountMetric.GaugeSet(m.prometheus.dashboardWidgetCount)
+		teamDashboardCountMetric.GaugeSet(m.prometheus.teamDashboardCount)
+		dashboardQueryMetric.GaugeSet(m.prometheus.dashboardQuery)
+	}
+}
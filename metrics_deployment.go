@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -10,13 +15,35 @@ import (
 	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
 )
 
+// production environment names (deployment.prod-environment), nil if disabled
+var deploymentProdEnvironmentRegexp *regexp.Regexp
+
 type MetricsCollectorDeployment struct {
 	CollectorProcessorProject
 
 	prometheus struct {
 		deployment       *prometheus.GaugeVec
 		deploymentStatus *prometheus.GaugeVec
+
+		deploymentPhaseDuration *prometheus.GaugeVec
+		deploymentBlockingGate  *prometheus.GaugeVec
+
+		environmentConcurrentDeployments *prometheus.GaugeVec
+		prodDeployingPipelines           *prometheus.GaugeVec
+		deploymentReason                 *prometheus.GaugeVec
+
+		deploymentInterval *prometheus.SummaryVec
+		deploymentRecovery *prometheus.SummaryVec
+
+		deploymentDurationHistogram *prometheus.HistogramVec
 	}
+
+	// successful deployments already observed for deployment interval and recovery (per release definition)
+	deploymentObservedLock sync.Mutex
+	deploymentObservedList map[string]map[int64]bool
+
+	// completed deployments already observed for the deployment duration histogram (per release definition)
+	deploymentDurationObservedList map[string]map[int64]bool
 }
 
 func (m *MetricsCollectorDeployment) Setup(collector *CollectorProject) {
@@ -42,6 +69,7 @@ func (m *MetricsCollectorDeployment) Setup(collector *CollectorProject) {
 			"environmentId",
 			"environmentName",
 			"approvedBy",
+			"triggeringBuildDefinitionId",
 		},
 	)
 	prometheus.MustRegister(m.prometheus.deployment)
@@ -58,40 +86,230 @@ func (m *MetricsCollectorDeployment) Setup(collector *CollectorProject) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.deploymentStatus)
+
+	m.prometheus.deploymentPhaseDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_deployment_phase_duration_seconds",
+			Help: "Azure DevOps deployment phase duration",
+		},
+		[]string{
+			"projectID",
+			"deploymentID",
+			"environmentName",
+			"phase",
+			"phaseType",
+			"status",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentPhaseDuration)
+
+	m.prometheus.deploymentBlockingGate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_deployment_blocking_gate",
+			Help: "Azure DevOps deployment gates which are failing or pending in the latest evaluation",
+		},
+		[]string{
+			"projectID",
+			"deploymentID",
+			"releaseDefinitionID",
+			"environmentName",
+			"phase",
+			"gateType",
+			"gateName",
+			"status",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentBlockingGate)
+
+	m.prometheus.environmentConcurrentDeployments = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_environment_concurrent_deployments",
+			Help: "Azure DevOps number of deployments in progress per release environment",
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.environmentConcurrentDeployments)
+
+	m.prometheus.prodDeployingPipelines = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_prod_deploying_pipelines",
+			Help: "Azure DevOps number of distinct release definitions with a successful deployment to a production environment today",
+		},
+		[]string{
+			"projectID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.prodDeployingPipelines)
+
+	m.prometheus.deploymentReason = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_deployment_reason",
+			Help: "Azure DevOps reason of latest deployment per release environment (enum, see README)",
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentReason)
+
+	m.prometheus.deploymentInterval = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "azure_devops_deployment_interval_seconds",
+			Help:       "Azure DevOps interval between consecutive successful deployments per release environment",
+			MaxAge:     *opts.Stats.SummaryMaxAge,
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentInterval)
+
+	m.prometheus.deploymentRecovery = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "azure_devops_deployment_recovery_seconds",
+			Help:       "Azure DevOps time from first failed deployment to next successful deployment per release environment",
+			MaxAge:     *opts.Stats.SummaryMaxAge,
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentRecovery)
+
+	m.prometheus.deploymentDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "azure_devops_deployment_duration_hist_seconds",
+			Help: "Azure DevOps duration of completed deployments per release environment (each deployment is observed once)",
+			Buckets: []float64{
+				1 * 60,      // 1m
+				2 * 60,      // 2m
+				5 * 60,      // 5m
+				10 * 60,     // 10m
+				15 * 60,     // 15m
+				30 * 60,     // 30m
+				60 * 60,     // 1h
+				2 * 60 * 60, // 2h
+				4 * 60 * 60, // 4h
+			},
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentDurationHistogram)
+
+	m.deploymentObservedList = map[string]map[int64]bool{}
+	m.deploymentDurationObservedList = map[string]map[int64]bool{}
+}
+
+// deploymentReasonValue maps the deployment reason to a numeric enum value (0 = none/unknown)
+func deploymentReasonValue(reason string) float64 {
+	switch strings.ToLower(reason) {
+	case "manual":
+		return 1
+	case "automated":
+		return 2
+	case "scheduled", "schedule":
+		return 3
+	case "redeploytrigger":
+		return 4
+	case "continuousintegration":
+		return 5
+	case "pullrequest":
+		return 6
+	default:
+		return 0
+	}
 }
 
 func (m *MetricsCollectorDeployment) Reset() {
 	m.prometheus.deployment.Reset()
 	m.prometheus.deploymentStatus.Reset()
+	m.prometheus.deploymentPhaseDuration.Reset()
+	m.prometheus.deploymentBlockingGate.Reset()
+	m.prometheus.environmentConcurrentDeployments.Reset()
+	m.prometheus.prodDeployingPipelines.Reset()
+	m.prometheus.deploymentReason.Reset()
 }
 
 func (m *MetricsCollectorDeployment) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
 	list, err := AzureDevopsClient.ListReleaseDefinitions(project.Id)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
 	deploymentMetric := prometheusCommon.NewMetricsList()
 	deploymentStatusMetric := prometheusCommon.NewMetricsList()
+	deploymentPhaseDurationMetric := prometheusCommon.NewMetricsList()
+	deploymentBlockingGateMetric := prometheusCommon.NewMetricsList()
+	environmentConcurrentDeploymentsMetric := prometheusCommon.NewMetricsList()
+	prodDeployingPipelinesMetric := prometheusCommon.NewMetricsList()
+	deploymentReasonMetric := prometheusCommon.NewMetricsList()
+
+	labelLimiter := newLabelValueLimiter()
+
+	// releases are fetched once per collection and shared between deployments
+	releaseCache := map[int64]*devopsClient.Release{}
+
+	// release definitions with a successful deployment today per production environment
+	now := time.Now()
+	dayStartTime := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	prodDeployingPipelines := map[string]map[int64]bool{}
 
 	for _, releaseDefinition := range list.List {
 		contextLogger := logger.WithField("releaseDefinition", releaseDefinition.Name)
 
 		deploymentList, err := AzureDevopsClient.ListReleaseDeployments(project.Id, releaseDefinition.Id)
 		if err != nil {
-			contextLogger.Error(err)
+			logError(ctx, contextLogger, err)
 			return
 		}
 
+		concurrentDeployments := map[string]int64{}
+		for _, environment := range releaseDefinition.Environments {
+			concurrentDeployments[environment.Name] = 0
+
+			if deploymentProdEnvironmentRegexp != nil && deploymentProdEnvironmentRegexp.MatchString(environment.Name) {
+				if _, exists := prodDeployingPipelines[environment.Name]; !exists {
+					prodDeployingPipelines[environment.Name] = map[int64]bool{}
+				}
+			}
+		}
+
+		latestDeployments := map[string]devopsClient.ReleaseDeployment{}
+
 		for _, deployment := range deploymentList.List {
+			if deployment.DeploymentStatus == "inProgress" {
+				concurrentDeployments[deployment.ReleaseEnvironment.Name]++
+			}
+
+			if latestDeployment, exists := latestDeployments[deployment.ReleaseEnvironment.Name]; !exists || deployment.Id > latestDeployment.Id {
+				latestDeployments[deployment.ReleaseEnvironment.Name] = deployment
+			}
+
 			deploymentMetric.AddInfo(prometheus.Labels{
 				"projectID":           project.Id,
 				"deploymentID":        int64ToString(deployment.Id),
 				"releaseID":           int64ToString(deployment.Release.Id),
 				"releaseName":         deployment.Release.Name,
 				"releaseDefinitionID": int64ToString(releaseDefinition.Id),
-				"requestedBy":         deployment.RequestedBy.DisplayName,
+				"requestedBy":         labelLimiter.Value("requestedBy", identityLabel(deployment.RequestedBy)),
 				"deploymentName":      deployment.Name,
 				"deploymentStatus":    deployment.DeploymentStatus,
 				"operationStatus":     deployment.OperationStatus,
@@ -99,7 +317,9 @@ func (m *MetricsCollectorDeployment) Collect(ctx context.Context, logger *log.En
 				"attempt":             int64ToString(deployment.Attempt),
 				"environmentId":       int64ToString(deployment.ReleaseEnvironment.Id),
 				"environmentName":     deployment.ReleaseEnvironment.Name,
-				"approvedBy":          deployment.ApprovedBy(),
+				"approvedBy":          identityListLabel(deployment.ApprovedBy()),
+
+				"triggeringBuildDefinitionId": deployment.TriggeringBuildDefinitionId(),
 			})
 
 			queuedOn := deployment.QueuedOnTime()
@@ -130,6 +350,15 @@ func (m *MetricsCollectorDeployment) Collect(ctx context.Context, logger *log.En
 				}, *completedOn)
 			}
 
+			if deploymentProdEnvironmentRegexp != nil && deployment.DeploymentStatus == "succeeded" && completedOn != nil && !completedOn.Before(dayStartTime) {
+				if deploymentProdEnvironmentRegexp.MatchString(deployment.ReleaseEnvironment.Name) {
+					if _, exists := prodDeployingPipelines[deployment.ReleaseEnvironment.Name]; !exists {
+						prodDeployingPipelines[deployment.ReleaseEnvironment.Name] = map[int64]bool{}
+					}
+					prodDeployingPipelines[deployment.ReleaseEnvironment.Name][releaseDefinition.Id] = true
+				}
+			}
+
 			if completedOn != nil && startedOn != nil {
 				deploymentStatusMetric.AddDuration(prometheus.Labels{
 					"projectID":    project.Id,
@@ -137,11 +366,257 @@ func (m *MetricsCollectorDeployment) Collect(ctx context.Context, logger *log.En
 					"type":         "jobDuration",
 				}, completedOn.Sub(*startedOn))
 			}
+
+			if opts.Deployment.Phases || opts.Deployment.BlockingGates {
+				release, ok := releaseCache[deployment.Release.Id]
+				if !ok {
+					if val, err := AzureDevopsClient.GetRelease(project.Id, deployment.Release.Id); err == nil {
+						release = &val
+					} else {
+						logError(ctx, contextLogger, err)
+					}
+					releaseCache[deployment.Release.Id] = release
+				}
+
+				if release != nil && opts.Deployment.Phases {
+					m.collectDeploymentPhases(deploymentPhaseDurationMetric, project, deployment, *release)
+				}
+
+				if release != nil && opts.Deployment.BlockingGates {
+					m.collectDeploymentBlockingGates(deploymentBlockingGateMetric, project, releaseDefinition, deployment, *release)
+				}
+			}
+		}
+
+		for environmentName, count := range concurrentDeployments {
+			environmentConcurrentDeploymentsMetric.Add(prometheus.Labels{
+				"projectID":           project.Id,
+				"releaseDefinitionID": int64ToString(releaseDefinition.Id),
+				"environmentName":     environmentName,
+			}, float64(count))
 		}
+
+		for environmentName, deployment := range latestDeployments {
+			deploymentReasonMetric.Add(prometheus.Labels{
+				"projectID":           project.Id,
+				"releaseDefinitionID": int64ToString(releaseDefinition.Id),
+				"environmentName":     environmentName,
+			}, deploymentReasonValue(deployment.Reason))
+		}
+
+		m.collectDeploymentTransitions(project, releaseDefinition, deploymentList)
+		m.collectDeploymentDurations(project, releaseDefinition, deploymentList)
+	}
+
+	for environmentName, releaseDefinitions := range prodDeployingPipelines {
+		prodDeployingPipelinesMetric.Add(prometheus.Labels{
+			"projectID":       project.Id,
+			"environmentName": environmentName,
+		}, float64(len(releaseDefinitions)))
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(deploymentMetric, deploymentStatusMetric, deploymentPhaseDurationMetric, deploymentBlockingGateMetric, environmentConcurrentDeploymentsMetric, prodDeployingPipelinesMetric, deploymentReasonMetric)
 		deploymentMetric.GaugeSet(m.prometheus.deployment)
 		deploymentStatusMetric.GaugeSet(m.prometheus.deploymentStatus)
+		deploymentPhaseDurationMetric.GaugeSet(m.prometheus.deploymentPhaseDuration)
+		deploymentBlockingGateMetric.GaugeSet(m.prometheus.deploymentBlockingGate)
+		environmentConcurrentDeploymentsMetric.GaugeSet(m.prometheus.environmentConcurrentDeployments)
+		prodDeployingPipelinesMetric.GaugeSet(m.prometheus.prodDeployingPipelines)
+		deploymentReasonMetric.GaugeSet(m.prometheus.deploymentReason)
+	}
+}
+
+// collectDeploymentTransitions observes the gap between consecutive successful deployments (interval) and
+// the time from the first failed to the next successful deployment (recovery) per environment,
+// every successful deployment is only observed once across collections
+func (m *MetricsCollectorDeployment) collectDeploymentTransitions(project devopsClient.Project, releaseDefinition devopsClient.ReleaseDefinition, deploymentList devopsClient.ReleaseDeploymentList) {
+	environmentDeployments := map[string][]devopsClient.ReleaseDeployment{}
+	for _, deployment := range deploymentList.List {
+		if deployment.CompletedOnTime() == nil {
+			continue
+		}
+
+		switch deployment.DeploymentStatus {
+		case "succeeded", "failed", "partiallySucceeded":
+			environmentName := deployment.ReleaseEnvironment.Name
+			environmentDeployments[environmentName] = append(environmentDeployments[environmentName], deployment)
+		}
+	}
+
+	observedKey := project.Id + ":" + int64ToString(releaseDefinition.Id)
+
+	m.deploymentObservedLock.Lock()
+	defer m.deploymentObservedLock.Unlock()
+
+	// only keep deployments which are still listed, older ones will not show up again
+	previousObserved := m.deploymentObservedList[observedKey]
+	observed := map[int64]bool{}
+
+	for environmentName, deployments := range environmentDeployments {
+		sort.Slice(deployments, func(i, j int) bool {
+			return deployments[i].CompletedOnTime().Before(*deployments[j].CompletedOnTime())
+		})
+
+		environmentLabels := prometheus.Labels{
+			"projectID":           project.Id,
+			"releaseDefinitionID": int64ToString(releaseDefinition.Id),
+			"environmentName":     environmentName,
+		}
+
+		var lastSuccess, firstFailure *time.Time
+		for _, deployment := range deployments {
+			completedOn := deployment.CompletedOnTime()
+
+			if deployment.DeploymentStatus != "succeeded" {
+				if firstFailure == nil {
+					firstFailure = completedOn
+				}
+				continue
+			}
+
+			if lastSuccess != nil || firstFailure != nil {
+				observed[deployment.Id] = true
+			}
+
+			if !previousObserved[deployment.Id] {
+				if lastSuccess != nil {
+					m.prometheus.deploymentInterval.With(environmentLabels).Observe(completedOn.Sub(*lastSuccess).Seconds())
+				}
+
+				if firstFailure != nil {
+					m.prometheus.deploymentRecovery.With(environmentLabels).Observe(completedOn.Sub(*firstFailure).Seconds())
+				}
+			}
+
+			lastSuccess = completedOn
+			firstFailure = nil
+		}
+	}
+
+	m.deploymentObservedList[observedKey] = observed
+}
+
+// collectDeploymentDurations observes the duration of completed deployments,
+// every deployment is only observed once across collections
+func (m *MetricsCollectorDeployment) collectDeploymentDurations(project devopsClient.Project, releaseDefinition devopsClient.ReleaseDefinition, deploymentList devopsClient.ReleaseDeploymentList) {
+	observedKey := project.Id + ":" + int64ToString(releaseDefinition.Id)
+
+	m.deploymentObservedLock.Lock()
+	defer m.deploymentObservedLock.Unlock()
+
+	// only keep deployments which are still listed, older ones will not show up again
+	previousObserved := m.deploymentDurationObservedList[observedKey]
+	observed := map[int64]bool{}
+
+	for _, deployment := range deploymentList.List {
+		switch deployment.DeploymentStatus {
+		case "succeeded", "failed", "partiallySucceeded":
+		default:
+			continue
+		}
+
+		startedOn := deployment.StartedOnTime()
+		completedOn := deployment.CompletedOnTime()
+		if startedOn == nil || completedOn == nil {
+			continue
+		}
+
+		observed[deployment.Id] = true
+		if previousObserved[deployment.Id] {
+			continue
+		}
+
+		m.prometheus.deploymentDurationHistogram.With(prometheus.Labels{
+			"projectID":           project.Id,
+			"releaseDefinitionID": int64ToString(releaseDefinition.Id),
+			"environmentName":     deployment.ReleaseEnvironment.Name,
+		}).Observe(completedOn.Sub(*startedOn).Seconds())
+	}
+
+	m.deploymentDurationObservedList[observedKey] = observed
+}
+
+func (m *MetricsCollectorDeployment) collectDeploymentPhases(metric *prometheusCommon.MetricList, project devopsClient.Project, deployment devopsClient.ReleaseDeployment, release devopsClient.Release) {
+	for _, environment := range release.Environments {
+		if environment.Id != deployment.ReleaseEnvironment.Id {
+			continue
+		}
+
+		for _, deployStep := range environment.DeploySteps {
+			if deployStep.DeploymentId != deployment.Id {
+				continue
+			}
+
+			if duration := deployStep.PreDeploymentGates.Duration(); duration > 0 {
+				metric.AddDuration(prometheus.Labels{
+					"projectID":       project.Id,
+					"deploymentID":    int64ToString(deployment.Id),
+					"environmentName": environment.Name,
+					"phase":           "preDeploymentGates",
+					"phaseType":       "deploymentGates",
+					"status":          deployStep.PreDeploymentGates.Status,
+				}, duration)
+			}
+
+			for _, phase := range deployStep.ReleaseDeployPhases {
+				if duration := phase.Duration(); duration > 0 {
+					metric.AddDuration(prometheus.Labels{
+						"projectID":       project.Id,
+						"deploymentID":    int64ToString(deployment.Id),
+						"environmentName": environment.Name,
+						"phase":           phase.Name,
+						"phaseType":       phase.PhaseType,
+						"status":          phase.Status,
+					}, duration)
+				}
+			}
+
+			if duration := deployStep.PostDeploymentGates.Duration(); duration > 0 {
+				metric.AddDuration(prometheus.Labels{
+					"projectID":       project.Id,
+					"deploymentID":    int64ToString(deployment.Id),
+					"environmentName": environment.Name,
+					"phase":           "postDeploymentGates",
+					"phaseType":       "deploymentGates",
+					"status":          deployStep.PostDeploymentGates.Status,
+				}, duration)
+			}
+		}
+	}
+}
+
+// collectDeploymentBlockingGates reports the gates of the deployment which are currently failing or pending
+func (m *MetricsCollectorDeployment) collectDeploymentBlockingGates(metric *prometheusCommon.MetricList, project devopsClient.Project, releaseDefinition devopsClient.ReleaseDefinition, deployment devopsClient.ReleaseDeployment, release devopsClient.Release) {
+	for _, environment := range release.Environments {
+		if environment.Id != deployment.ReleaseEnvironment.Id {
+			continue
+		}
+
+		for _, deployStep := range environment.DeploySteps {
+			if deployStep.DeploymentId != deployment.Id {
+				continue
+			}
+
+			gatesList := map[string]devopsClient.ReleaseEnvironmentDeployStepGates{
+				"preDeploymentGates":  deployStep.PreDeploymentGates,
+				"postDeploymentGates": deployStep.PostDeploymentGates,
+			}
+
+			for phase, gates := range gatesList {
+				for _, gate := range gates.BlockingGates() {
+					metric.AddInfo(prometheus.Labels{
+						"projectID":           project.Id,
+						"deploymentID":        int64ToString(deployment.Id),
+						"releaseDefinitionID": int64ToString(releaseDefinition.Id),
+						"environmentName":     environment.Name,
+						"phase":               phase,
+						"gateType":            gate.GateType(),
+						"gateName":            gate.Name,
+						"status":              gate.Status,
+					})
+				}
+			}
+		}
 	}
 }
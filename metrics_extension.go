@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+)
+
+type MetricsCollectorExtension struct {
+	CollectorProcessorGeneral
+
+	prometheus struct {
+		extension *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorExtension) Setup(collector *CollectorGeneral) {
+	m.CollectorReference = collector
+
+	m.prometheus.extension = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_extension_info",
+			Help: "Azure DevOps installed extension",
+		},
+		[]string{
+			"publisher",
+			"extensionId",
+			"extensionName",
+			"version",
+			"enabled",
+			"builtIn",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.extension)
+}
+
+func (m *MetricsCollectorExtension) Reset() {
+	m.prometheus.extension.Reset()
+}
+
+func (m *MetricsCollectorExtension) Collect(ctx context.Context, logger *log.Entry, callback chan<- func()) {
+	list, err := AzureDevopsClient.ListInstalledExtensions()
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	extensionMetric := prometheusCommon.NewMetricsList()
+
+	for _, extension := range list.List {
+		extensionMetric.AddInfo(prometheus.Labels{
+			"publisher":     extension.PublisherId,
+			"extensionId":   extension.ExtensionId,
+			"extensionName": extension.ExtensionName,
+			"version":       extension.Version,
+			"enabled":       boolToString(extension.IsEnabled()),
+			"builtIn":       boolToString(extension.IsBuiltIn()),
+		})
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(extensionMetric)
+		extensionMetric.GaugeSet(m.prometheus.extension)
+	}
+}
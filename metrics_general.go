@@ -71,15 +71,18 @@ func (m *MetricsCollectorGeneral) collectCollectorStats(ctx context.Context, log
 	}
 
 	for _, collector := range collectorQueryList {
-		if collector.LastScrapeDuration != nil {
-			statsMetrics.AddDuration(prometheus.Labels{
-				"name": collector.Name,
-				"type": "collectorDuration",
-			}, *collector.LastScrapeDuration)
+		for _, scheduler := range collector.SchedulerList {
+			if scheduler.LastScrapeDuration != nil {
+				statsMetrics.AddDuration(prometheus.Labels{
+					"name": scheduler.Name,
+					"type": "collectorDuration",
+				}, *scheduler.LastScrapeDuration)
+			}
 		}
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(statsMetrics)
 		statsMetrics.GaugeSet(m.prometheus.stats)
 	}
 }
@@ -68,12 +68,13 @@ func (m *MetricsCollectorLatestBuild) Reset() {
 func (m *MetricsCollectorLatestBuild) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
 	list, err := AzureDevopsClient.ListLatestBuilds(project.Id)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
 	buildMetric := prometheusCommon.NewMetricsList()
 	buildStatusMetric := prometheusCommon.NewMetricsList()
+	labelLimiter := newLabelValueLimiter()
 
 	for _, build := range list.List {
 		buildMetric.AddInfo(prometheus.Labels{
@@ -83,7 +84,7 @@ func (m *MetricsCollectorLatestBuild) Collect(ctx context.Context, logger *log.E
 			"buildNumber":       build.BuildNumber,
 			"buildName":         build.Definition.Name,
 			"agentPoolID":       int64ToString(build.Queue.Pool.Id),
-			"requestedBy":       build.RequestedBy.DisplayName,
+			"requestedBy":       labelLimiter.Value("requestedBy", identityLabel(build.RequestedBy)),
 			"sourceBranch":      build.SourceBranch,
 			"sourceVersion":     build.SourceVersion,
 			"status":            build.Status,
@@ -122,6 +123,7 @@ func (m *MetricsCollectorLatestBuild) Collect(ctx context.Context, logger *log.E
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(buildMetric, buildStatusMetric)
 		buildMetric.GaugeSet(m.prometheus.build)
 		buildStatusMetric.GaugeSet(m.prometheus.buildStatus)
 	}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+type MetricsCollectorPipelineApproval struct {
+	CollectorProcessorProject
+
+	prometheus struct {
+		pipelineApprovalStatus          *prometheus.GaugeVec
+		pipelineApprovalPendingDuration *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorPipelineApproval) Setup(collector *CollectorProject) {
+	m.CollectorReference = collector
+
+	m.prometheus.pipelineApprovalStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pipeline_approval_status",
+			Help: "Azure DevOps pipeline (yaml) approval status (value is creation timestamp)",
+		},
+		[]string{
+			"projectID",
+			"pipelineId",
+			"approvalId",
+			"status",
+			"approvedBy",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pipelineApprovalStatus)
+
+	m.prometheus.pipelineApprovalPendingDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pipeline_approval_pending_duration_seconds",
+			Help: "Azure DevOps pipeline (yaml) approval pending duration",
+		},
+		[]string{
+			"projectID",
+			"pipelineId",
+			"approvalId",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pipelineApprovalPendingDuration)
+}
+
+func (m *MetricsCollectorPipelineApproval) Reset() {
+	m.prometheus.pipelineApprovalStatus.Reset()
+	m.prometheus.pipelineApprovalPendingDuration.Reset()
+}
+
+func (m *MetricsCollectorPipelineApproval) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	list, err := AzureDevopsClient.ListPipelineApprovals(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	pipelineApprovalStatusMetric := prometheusCommon.NewMetricsList()
+	pipelineApprovalPendingDurationMetric := prometheusCommon.NewMetricsList()
+
+	for _, approval := range list.List {
+		pipelineApprovalStatusMetric.AddTime(prometheus.Labels{
+			"projectID":  project.Id,
+			"pipelineId": approval.Pipeline.Id,
+			"approvalId": approval.Id,
+			"status":     approval.Status,
+			"approvedBy": identityListLabel(approval.ApprovedBy()),
+		}, approval.CreatedOn)
+
+		if approval.IsPending() {
+			pipelineApprovalPendingDurationMetric.AddDuration(prometheus.Labels{
+				"projectID":  project.Id,
+				"pipelineId": approval.Pipeline.Id,
+				"approvalId": approval.Id,
+			}, time.Since(approval.CreatedOn))
+		}
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(pipelineApprovalStatusMetric, pipelineApprovalPendingDurationMetric)
+		pipelineApprovalStatusMetric.GaugeSet(m.prometheus.pipelineApprovalStatus)
+		pipelineApprovalPendingDurationMetric.GaugeSet(m.prometheus.pipelineApprovalPendingDuration)
+	}
+}
@@ -52,6 +52,7 @@ func (m *MetricsCollectorProject) collectProject(ctx context.Context, logger *lo
 	})
 
 	callback <- func() {
+		m.CollectorReference.countSeries(projectMetric)
 		projectMetric.GaugeSet(m.prometheus.project)
 	}
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -17,7 +19,18 @@ type MetricsCollectorPullRequest struct {
 		pullRequest       *prometheus.GaugeVec
 		pullRequestStatus *prometheus.GaugeVec
 		pullRequestLabel  *prometheus.GaugeVec
+
+		pullRequestAutoComplete *prometheus.GaugeVec
+		pullRequestIterations   *prometheus.GaugeVec
+
+		pullRequestTargetBranchCount *prometheus.GaugeVec
+
+		pullRequestMergeDuration *prometheus.HistogramVec
 	}
+
+	// completed pullrequests already observed for the merge duration histogram (per repository)
+	mergeDurationObservedLock sync.Mutex
+	mergeDurationObservedList map[string]map[int64]bool
 }
 
 func (m *MetricsCollectorPullRequest) Setup(collector *CollectorProject) {
@@ -71,36 +84,116 @@ func (m *MetricsCollectorPullRequest) Setup(collector *CollectorProject) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.pullRequestLabel)
+
+	m.prometheus.pullRequestAutoComplete = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pullrequest_autocomplete",
+			Help: "Azure DevOps pullrequest has auto-complete enabled (with merge blocking reason)",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+			"pullrequestID",
+			"blockingReason",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pullRequestAutoComplete)
+
+	m.prometheus.pullRequestTargetBranchCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pullrequest_target_branch_count",
+			Help: "Azure DevOps number of active pullrequests per target branch",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+			"targetBranch",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pullRequestTargetBranchCount)
+
+	m.prometheus.pullRequestMergeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "azure_devops_pullrequest_merge_duration_seconds",
+			Help: "Azure DevOps duration from creation to completion of pullrequests (within limit.pullrequest-history-duration)",
+			Buckets: []float64{
+				1 * 60 * 60,       // 1h
+				4 * 60 * 60,       // 4h
+				8 * 60 * 60,       // 8h
+				24 * 60 * 60,      // 1d
+				2 * 24 * 60 * 60,  // 2d
+				3 * 24 * 60 * 60,  // 3d
+				7 * 24 * 60 * 60,  // 7d
+				14 * 24 * 60 * 60, // 14d
+				30 * 24 * 60 * 60, // 30d
+			},
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pullRequestMergeDuration)
+
+	m.prometheus.pullRequestIterations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_pullrequest_iteration_count",
+			Help: "Azure DevOps number of iterations (pushed updates) of active pullrequests",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+			"pullrequestID",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.pullRequestIterations)
+
+	m.mergeDurationObservedList = map[string]map[int64]bool{}
 }
 
 func (m *MetricsCollectorPullRequest) Reset() {
 	m.prometheus.pullRequest.Reset()
 	m.prometheus.pullRequestStatus.Reset()
 	m.prometheus.pullRequestLabel.Reset()
-
+	m.prometheus.pullRequestAutoComplete.Reset()
+	m.prometheus.pullRequestTargetBranchCount.Reset()
+	m.prometheus.pullRequestIterations.Reset()
 }
 
 func (m *MetricsCollectorPullRequest) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	labelLimiter := newLabelValueLimiter()
+
 	for _, repository := range project.RepositoryList.List {
 		if repository.Disabled() {
 			continue
 		}
 
 		contextLogger := logger.WithField("repository", repository.Name)
-		m.collectPullRequests(ctx, contextLogger, callback, project, repository)
+		m.collectPullRequests(ctx, contextLogger, callback, project, repository, labelLimiter)
+
+		if opts.PullRequest.MergeDuration {
+			m.collectCompletedPullRequests(ctx, contextLogger, project, repository)
+		}
 	}
 }
 
-func (m *MetricsCollectorPullRequest) collectPullRequests(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project, repository devopsClient.Repository) {
+func (m *MetricsCollectorPullRequest) collectPullRequests(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project, repository devopsClient.Repository, labelLimiter *labelValueLimiter) {
 	list, err := AzureDevopsClient.ListPullrequest(project.Id, repository.Id)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
 	pullRequestMetric := prometheusCommon.NewMetricsList()
 	pullRequestStatusMetric := prometheusCommon.NewMetricsList()
 	pullRequestLabelMetric := prometheusCommon.NewMetricsList()
+	pullRequestAutoCompleteMetric := prometheusCommon.NewMetricsList()
+	pullRequestTargetBranchCountMetric := prometheusCommon.NewHashedMetricsList()
+	pullRequestIterationsMetric := prometheusCommon.NewMetricsList()
 
 	for _, pullRequest := range list.List {
 		voteSummary := pullRequest.GetVoteSummary()
@@ -112,12 +205,19 @@ func (m *MetricsCollectorPullRequest) collectPullRequests(ctx context.Context, l
 			"pullrequestTitle": pullRequest.Title,
 			"status":           pullRequest.Status,
 			"voteStatus":       voteSummary.HumanizeString(),
-			"creator":          pullRequest.CreatedBy.DisplayName,
+			"creator":          labelLimiter.Value("creator", identityLabel(pullRequest.CreatedBy)),
 			"isDraft":          boolToString(pullRequest.IsDraft),
 			"sourceBranch":     pullRequest.SourceRefName,
 			"targetBranch":     pullRequest.TargetRefName,
 		})
 
+		pullRequestTargetBranchCountMetric.Inc(prometheus.Labels{
+			"projectID":      project.Id,
+			"repositoryID":   repository.Id,
+			"repositoryName": repository.Name,
+			"targetBranch":   pullRequest.TargetRefName,
+		})
+
 		pullRequestStatusMetric.AddTime(prometheus.Labels{
 			"projectID":     project.Id,
 			"repositoryID":  repository.Id,
@@ -125,6 +225,28 @@ func (m *MetricsCollectorPullRequest) collectPullRequests(ctx context.Context, l
 			"type":          "created",
 		}, pullRequest.CreationDate)
 
+		pullRequestAutoCompleteMetric.AddBool(prometheus.Labels{
+			"projectID":      project.Id,
+			"repositoryID":   repository.Id,
+			"repositoryName": repository.Name,
+			"pullrequestID":  int64ToString(pullRequest.Id),
+			"blockingReason": pullRequest.MergeBlockingReason(),
+		}, pullRequest.IsAutoComplete())
+
+		if opts.PullRequest.Iterations {
+			iterationList, err := AzureDevopsClient.ListPullRequestIterations(project.Id, repository.Id, pullRequest.Id)
+			if err == nil {
+				pullRequestIterationsMetric.Add(prometheus.Labels{
+					"projectID":      project.Id,
+					"repositoryID":   repository.Id,
+					"repositoryName": repository.Name,
+					"pullrequestID":  int64ToString(pullRequest.Id),
+				}, float64(len(iterationList.List)))
+			} else {
+				logWarn(logger, err)
+			}
+		}
+
 		for _, label := range pullRequest.Labels {
 			pullRequestLabelMetric.AddInfo(prometheus.Labels{
 				"projectID":     project.Id,
@@ -137,8 +259,52 @@ func (m *MetricsCollectorPullRequest) collectPullRequests(ctx context.Context, l
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(pullRequestMetric, pullRequestStatusMetric, pullRequestLabelMetric, pullRequestAutoCompleteMetric, pullRequestTargetBranchCountMetric, pullRequestIterationsMetric)
 		pullRequestMetric.GaugeSet(m.prometheus.pullRequest)
 		pullRequestStatusMetric.GaugeSet(m.prometheus.pullRequestStatus)
 		pullRequestLabelMetric.GaugeSet(m.prometheus.pullRequestLabel)
+		pullRequestAutoCompleteMetric.GaugeSet(m.prometheus.pullRequestAutoComplete)
+		pullRequestTargetBranchCountMetric.GaugeSet(m.prometheus.pullRequestTargetBranchCount)
+		pullRequestIterationsMetric.GaugeSet(m.prometheus.pullRequestIterations)
 	}
 }
+
+// collectCompletedPullRequests observes the merge duration of completed pullrequests,
+// every pullrequest is only observed once across collections
+func (m *MetricsCollectorPullRequest) collectCompletedPullRequests(ctx context.Context, logger *log.Entry, project devopsClient.Project, repository devopsClient.Repository) {
+	list, err := AzureDevopsClient.ListCompletedPullrequest(project.Id, repository.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	observedKey := project.Id + ":" + repository.Id
+
+	m.mergeDurationObservedLock.Lock()
+	defer m.mergeDurationObservedLock.Unlock()
+
+	// only keep pullrequests which are still listed, older ones will not show up again
+	previousObserved := m.mergeDurationObservedList[observedKey]
+	observed := map[int64]bool{}
+
+	minTime := time.Now().Add(-opts.Limit.PullRequestHistoryDuration)
+
+	for _, pullRequest := range list.List {
+		if pullRequest.ClosedDate.Before(minTime) {
+			continue
+		}
+
+		observed[pullRequest.Id] = true
+		if previousObserved[pullRequest.Id] {
+			continue
+		}
+
+		m.prometheus.pullRequestMergeDuration.With(prometheus.Labels{
+			"projectID":      project.Id,
+			"repositoryID":   repository.Id,
+			"repositoryName": repository.Name,
+		}).Observe(pullRequest.MergeDuration().Seconds())
+	}
+
+	m.mergeDurationObservedList[observedKey] = observed
+}
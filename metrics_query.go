@@ -2,20 +2,28 @@ package main
 
 import (
 	"context"
-	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
 )
 
 type MetricsCollectorQuery struct {
 	CollectorProcessorQuery
 
 	prometheus struct {
-		workItemCount *prometheus.GaugeVec
-		workItemData  *prometheus.GaugeVec
+		workItemCount         *prometheus.GaugeVec
+		workItemCountAreaPath *prometheus.GaugeVec
+		workItemData          *prometheus.GaugeVec
+		workItemChildren      *prometheus.GaugeVec
+		workItemGroupCount    *prometheus.GaugeVec
+		backlogStateCount     *prometheus.GaugeVec
 	}
+
+	// grouped work item fields of all queries (label names are shared across queries)
+	groupByFieldList []string
 }
 
 func (m *MetricsCollectorQuery) Setup(collector *CollectorQuery) {
@@ -34,6 +42,19 @@ func (m *MetricsCollectorQuery) Setup(collector *CollectorQuery) {
 	)
 	prometheus.MustRegister(m.prometheus.workItemCount)
 
+	m.prometheus.workItemCountAreaPath = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_query_result_areapath",
+			Help: "Azure DevOps Query Result per area path (query option ';areaPathCount=true')",
+		},
+		[]string{
+			"projectId",
+			"queryPath",
+			"areaPath",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.workItemCountAreaPath)
+
 	m.prometheus.workItemData = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "azure_devops_workitem_data",
@@ -52,41 +73,148 @@ func (m *MetricsCollectorQuery) Setup(collector *CollectorQuery) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.workItemData)
+
+	m.prometheus.workItemChildren = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_workitem_children_count",
+			Help: "Azure DevOps number of child WorkItems per parent type and child state",
+		},
+		[]string{
+			"projectId",
+			"queryPath",
+			"parentType",
+			"state",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.workItemChildren)
+
+	groupByLabels := []string{
+		"projectId",
+		"queryPath",
+	}
+	groupByFieldExists := map[string]bool{}
+	for _, query := range collector.QueryList {
+		for _, fieldName := range query.GroupBy {
+			if !groupByFieldExists[fieldName] {
+				groupByFieldExists[fieldName] = true
+				m.groupByFieldList = append(m.groupByFieldList, fieldName)
+				groupByLabels = append(groupByLabels, queryGroupByLabel(fieldName))
+			}
+		}
+	}
+
+	m.prometheus.workItemGroupCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_query_count",
+			Help: "Azure DevOps Query Result grouped by work item fields (query option ';groupBy=<fields>')",
+		},
+		groupByLabels,
+	)
+	prometheus.MustRegister(m.prometheus.workItemGroupCount)
+
+	m.prometheus.backlogStateCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_backlog_state_count",
+			Help: "Azure DevOps backlog work items per state and work item type (query option ';backlog=true')",
+		},
+		[]string{
+			"projectId",
+			"queryPath",
+			"state",
+			"workItemType",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.backlogStateCount)
+}
+
+func (m *MetricsCollectorQuery) Reset(query *querySpec) {
+	for _, queryPath := range query.QueryPathList() {
+		queryLabels := prometheus.Labels{
+			"projectId": query.ProjectID,
+			"queryPath": queryPath,
+		}
+
+		m.prometheus.workItemCount.DeletePartialMatch(queryLabels)
+		m.prometheus.workItemCountAreaPath.DeletePartialMatch(queryLabels)
+		m.prometheus.workItemData.DeletePartialMatch(queryLabels)
+		m.prometheus.workItemChildren.DeletePartialMatch(queryLabels)
+		m.prometheus.workItemGroupCount.DeletePartialMatch(queryLabels)
+		m.prometheus.backlogStateCount.DeletePartialMatch(queryLabels)
+	}
 }
 
-func (m *MetricsCollectorQuery) Reset() {
-	m.prometheus.workItemCount.Reset()
+func (m *MetricsCollectorQuery) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), query *querySpec) {
+	if query.Folder {
+		m.collectQueryFolder(ctx, logger, callback, query)
+		return
+	}
+
+	m.collectQueryResults(ctx, logger, callback, query)
 }
 
-func (m *MetricsCollectorQuery) Collect(ctx context.Context, logger *log.Entry, callback chan<- func()) {
-	for _, query := range m.CollectorReference.QueryList {
-		queryPair := strings.Split(query, "@")
-		m.collectQueryResults(ctx, logger, callback, queryPair[0], queryPair[1])
+func (m *MetricsCollectorQuery) collectQueryFolder(ctx context.Context, logger *log.Entry, callback chan<- func(), query *querySpec) {
+	folder, err := AzureDevopsClient.GetQueryFolder(query.ProjectID, query.QueryPath, query.FolderDepth)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	for _, folderQuery := range folder.QueryList() {
+		contextLogger := logger.WithField("folderQuery", folderQuery.Path)
+		m.collectQueryResults(ctx, contextLogger, callback, query.folderQuery(folderQuery.Id))
 	}
 }
 
-func (m *MetricsCollectorQuery) collectQueryResults(ctx context.Context, logger *log.Entry, callback chan<- func(), queryPath string, projectID string) {
+func (m *MetricsCollectorQuery) collectQueryResults(ctx context.Context, logger *log.Entry, callback chan<- func(), query *querySpec) {
 	workItemsMetric := prometheusCommon.NewMetricsList()
+	workItemsAreaPathMetric := prometheusCommon.NewHashedMetricsList()
 	workItemsDataMetric := prometheusCommon.NewMetricsList()
+	workItemsChildrenMetric := prometheusCommon.NewHashedMetricsList()
+	workItemsGroupCountMetric := prometheusCommon.NewHashedMetricsList()
+	backlogStateCountMetric := prometheusCommon.NewHashedMetricsList()
+
+	labelLimiter := newLabelValueLimiter()
+
+	queryPath := query.QueryPath
+	projectID := query.ProjectID
 
 	workItemInfoList, err := AzureDevopsClient.QueryWorkItems(queryPath, projectID)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
-	workItemsMetric.Add(prometheus.Labels{
-		"projectId": projectID,
-		"queryPath": queryPath,
-	}, float64(len(workItemInfoList.List)))
+	workItemCount := 0
+	backlogFieldsMissing := 0
 
+	// child work item id -> work item type of parent, children are fetched in batches afterwards
+	childParentTypes := map[int64]string{}
+	childIds := []int64{}
 	for _, workItemInfo := range workItemInfoList.List {
-		workItem, err := AzureDevopsClient.GetWorkItem(workItemInfo.Url)
+		var workItem devopsClient.WorkItem
+		if query.Children {
+			workItem, err = AzureDevopsClient.GetWorkItemWithRelations(projectID, workItemInfo.Url)
+		} else {
+			workItem, err = AzureDevopsClient.GetWorkItem(projectID, workItemInfo.Url)
+		}
 		if err != nil {
-			logger.Error(err)
+			logError(ctx, logger, err)
 			return
 		}
 
+		if !query.MatchAreaPath(workItem.Fields.Path) {
+			continue
+		}
+		workItemCount++
+
+		if query.AreaPathCount {
+			workItemsAreaPathMetric.Inc(prometheus.Labels{
+				"projectId": projectID,
+				"queryPath": queryPath,
+				"areaPath":  workItem.Fields.Path,
+			})
+		}
+
 		workItemsDataMetric.AddInfo(prometheus.Labels{
 			"projectId":    projectID,
 			"queryPath":    queryPath,
@@ -98,10 +226,79 @@ func (m *MetricsCollectorQuery) collectQueryResults(ctx context.Context, logger
 			"resolvedDate": workItem.Fields.ResolvedDate,
 			"closedDate":   workItem.Fields.ClosedDate,
 		})
+
+		if len(query.GroupBy) > 0 {
+			groupLabels := prometheus.Labels{
+				"projectId": projectID,
+				"queryPath": queryPath,
+			}
+			// fields not grouped by this query are empty
+			for _, fieldName := range m.groupByFieldList {
+				groupLabels[queryGroupByLabel(fieldName)] = ""
+			}
+			for _, fieldName := range query.GroupBy {
+				labelName := queryGroupByLabel(fieldName)
+				groupLabels[labelName] = labelLimiter.Value(labelName, workItem.FieldValue(fieldName))
+			}
+			workItemsGroupCountMetric.Inc(groupLabels)
+		}
+
+		if query.Backlog {
+			if workItem.Fields.State == "" || workItem.Fields.WorkItemType == "" {
+				// state and type are needed for the backlog breakdown
+				backlogFieldsMissing++
+			} else {
+				backlogStateCountMetric.Inc(prometheus.Labels{
+					"projectId":    projectID,
+					"queryPath":    queryPath,
+					"state":        workItem.Fields.State,
+					"workItemType": workItem.Fields.WorkItemType,
+				})
+			}
+		}
+
+		if query.Children {
+			for _, childId := range workItem.ChildIds() {
+				if _, exists := childParentTypes[childId]; !exists {
+					childIds = append(childIds, childId)
+				}
+				childParentTypes[childId] = workItem.Fields.WorkItemType
+			}
+		}
+	}
+
+	if len(childIds) > 0 {
+		childList, err := AzureDevopsClient.GetWorkItemsBatch(projectID, childIds, []string{"System.State"})
+		if err != nil {
+			logWarn(logger, err)
+		} else {
+			for _, childWorkItem := range childList.List {
+				workItemsChildrenMetric.Inc(prometheus.Labels{
+					"projectId":  projectID,
+					"queryPath":  queryPath,
+					"parentType": childParentTypes[childWorkItem.Id],
+					"state":      childWorkItem.Fields.State,
+				})
+			}
+		}
+	}
+
+	if backlogFieldsMissing > 0 {
+		logger.Warnf("query returned %v work items without System.State or System.WorkItemType, ignored for backlog metrics", backlogFieldsMissing)
 	}
 
+	workItemsMetric.Add(prometheus.Labels{
+		"projectId": projectID,
+		"queryPath": queryPath,
+	}, float64(workItemCount))
+
 	callback <- func() {
+		m.CollectorReference.countSeries(workItemsMetric, workItemsAreaPathMetric, workItemsDataMetric, workItemsChildrenMetric, workItemsGroupCountMetric, backlogStateCountMetric)
 		workItemsMetric.GaugeSet(m.prometheus.workItemCount)
+		workItemsAreaPathMetric.GaugeSet(m.prometheus.workItemCountAreaPath)
 		workItemsDataMetric.GaugeSet(m.prometheus.workItemData)
+		workItemsChildrenMetric.GaugeSet(m.prometheus.workItemChildren)
+		workItemsGroupCountMetric.GaugeSet(m.prometheus.workItemGroupCount)
+		backlogStateCountMetric.GaugeSet(m.prometheus.backlogStateCount)
 	}
 }
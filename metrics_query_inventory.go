@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+type MetricsCollectorQueryInventory struct {
+	CollectorProcessorProject
+
+	prometheus struct {
+		queryInventory *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorQueryInventory) Setup(collector *CollectorProject) {
+	m.CollectorReference = collector
+
+	m.prometheus.queryInventory = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_query_inventory",
+			Help: "Azure DevOps number of saved work item queries per scope (shared or my queries of the exporter user)",
+		},
+		[]string{
+			"projectID",
+			"scope",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.queryInventory)
+}
+
+func (m *MetricsCollectorQueryInventory) Reset() {
+	m.prometheus.queryInventory.Reset()
+}
+
+func (m *MetricsCollectorQueryInventory) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	list, err := AzureDevopsClient.ListQueries(project.Id, queryFolderMaxDepth)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	queryInventoryMetric := prometheusCommon.NewMetricsList()
+
+	queryCount := map[string]int{
+		"shared": 0,
+		"my":     0,
+	}
+
+	for _, rootFolder := range list.List {
+		scope := "my"
+		if rootFolder.IsPublic {
+			scope = "shared"
+		}
+
+		queryCount[scope] += m.countQueries(logger, project, rootFolder)
+	}
+
+	for scope, count := range queryCount {
+		queryInventoryMetric.Add(prometheus.Labels{
+			"projectID": project.Id,
+			"scope":     scope,
+		}, float64(count))
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(queryInventoryMetric)
+		queryInventoryMetric.GaugeSet(m.prometheus.queryInventory)
+	}
+}
+
+// countQueries counts all queries inside the folder, folders deeper than the requested depth are fetched separately
+func (m *MetricsCollectorQueryInventory) countQueries(logger *log.Entry, project devopsClient.Project, folder devopsClient.QueryHierarchyItem) (count int) {
+	for _, item := range folder.Children {
+		if !item.IsFolder {
+			count++
+			continue
+		}
+
+		if item.HasChildren && len(item.Children) == 0 {
+			subFolder, err := AzureDevopsClient.GetQueryFolder(project.Id, item.Id, queryFolderMaxDepth)
+			if err != nil {
+				logWarn(logger, err)
+				continue
+			}
+			item = subFolder
+		}
+
+		count += m.countQueries(logger, project, item)
+	}
+
+	return
+}
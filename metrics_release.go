@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,6 +21,13 @@ type MetricsCollectorRelease struct {
 		releaseEnvironment         *prometheus.GaugeVec
 		releaseEnvironmentApproval *prometheus.GaugeVec
 		releaseEnvironmentStatus   *prometheus.GaugeVec
+		releaseArtifactAge         *prometheus.GaugeVec
+
+		environmentPendingPromotion *prometheus.GaugeVec
+		deploymentPendingApprover   *prometheus.GaugeVec
+
+		releaseDefinitionEnvironmentApprovalRequired *prometheus.GaugeVec
+		releaseDefinitionEnvironmentApproverCount    *prometheus.GaugeVec
 
 		releaseDefinition            *prometheus.GaugeVec
 		releaseDefinitionEnvironment *prometheus.GaugeVec
@@ -67,6 +75,19 @@ func (m *MetricsCollectorRelease) Setup(collector *CollectorProject) {
 	)
 	prometheus.MustRegister(m.prometheus.releaseArtifact)
 
+	m.prometheus.releaseArtifactAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_release_artifact_age_seconds",
+			Help: "Azure DevOps age of build artifact (build finish time) at creation of latest release",
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"artifactSourceId",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.releaseArtifactAge)
+
 	m.prometheus.releaseEnvironment = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "azure_devops_release_environment",
@@ -155,23 +176,86 @@ func (m *MetricsCollectorRelease) Setup(collector *CollectorProject) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.releaseDefinitionEnvironment)
+
+	m.prometheus.environmentPendingPromotion = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_environment_pending_promotion",
+			Help: "Azure DevOps release environment not yet deployed by the latest release of the definition",
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.environmentPendingPromotion)
+
+	m.prometheus.deploymentPendingApprover = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_deployment_pending_approver",
+			Help: "Azure DevOps approvers of pending deployment approvals (value is the creation time of the approval)",
+		},
+		[]string{
+			"projectID",
+			"releaseID",
+			"releaseDefinitionID",
+			"environmentName",
+			"approvalType",
+			"approverId",
+			"approverName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.deploymentPendingApprover)
+
+	m.prometheus.releaseDefinitionEnvironmentApprovalRequired = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_release_environment_approval_required",
+			Help: "Azure DevOps release definition environment requires manual approval (type pre or post deployment)",
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+			"type",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.releaseDefinitionEnvironmentApprovalRequired)
+
+	m.prometheus.releaseDefinitionEnvironmentApproverCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_release_environment_approver_count",
+			Help: "Azure DevOps number of configured approvers of release definition environment (type pre or post deployment)",
+		},
+		[]string{
+			"projectID",
+			"releaseDefinitionID",
+			"environmentName",
+			"type",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.releaseDefinitionEnvironmentApproverCount)
 }
 
 func (m *MetricsCollectorRelease) Reset() {
 	m.prometheus.release.Reset()
 	m.prometheus.releaseArtifact.Reset()
+	m.prometheus.releaseArtifactAge.Reset()
 	m.prometheus.releaseEnvironment.Reset()
 	m.prometheus.releaseEnvironmentApproval.Reset()
 	m.prometheus.releaseEnvironmentStatus.Reset()
 
 	m.prometheus.releaseDefinition.Reset()
 	m.prometheus.releaseDefinitionEnvironment.Reset()
+	m.prometheus.environmentPendingPromotion.Reset()
+	m.prometheus.deploymentPendingApprover.Reset()
+	m.prometheus.releaseDefinitionEnvironmentApprovalRequired.Reset()
+	m.prometheus.releaseDefinitionEnvironmentApproverCount.Reset()
 }
 
 func (m *MetricsCollectorRelease) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
 	list, err := AzureDevopsClient.ListReleaseDefinitions(project.Id)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
@@ -183,8 +267,20 @@ func (m *MetricsCollectorRelease) Collect(ctx context.Context, logger *log.Entry
 	releaseEnvironmentMetric := prometheusCommon.NewMetricsList()
 	releaseEnvironmentApprovalMetric := prometheusCommon.NewMetricsList()
 	releaseEnvironmentStatusMetric := prometheusCommon.NewMetricsList()
+	releaseArtifactAgeMetric := prometheusCommon.NewMetricsList()
+	environmentPendingPromotionMetric := prometheusCommon.NewMetricsList()
+	deploymentPendingApproverMetric := prometheusCommon.NewMetricsList()
+	releaseDefinitionEnvironmentApprovalRequiredMetric := prometheusCommon.NewMetricsList()
+	releaseDefinitionEnvironmentApproverCountMetric := prometheusCommon.NewMetricsList()
+
+	labelLimiter := newLabelValueLimiter()
+
+	// release definitions (filtered by path), used to filter the release history
+	releaseDefinitionList := map[int64]bool{}
 
 	for _, releaseDefinition := range list.List {
+		releaseDefinitionList[releaseDefinition.Id] = true
+
 		// --------------------------------------
 		// Release definition
 		releaseDefinitionMetric.AddInfo(prometheus.Labels{
@@ -203,11 +299,37 @@ func (m *MetricsCollectorRelease) Collect(ctx context.Context, logger *log.Entry
 				"environmentID":       int64ToString(environment.Id),
 				"environmentName":     environment.Name,
 				"rank":                int64ToString(environment.Rank),
-				"owner":               environment.Owner.DisplayName,
+				"owner":               identityLabel(environment.Owner),
 				"releaseID":           int64ToString(environment.CurrentRelease.Id),
 				"badgeUrl":            environment.BadgeUrl,
 			})
 		}
+
+		if opts.Release.ApprovalConfig {
+			// approval configuration is not included in the release definition list
+			releaseDefinitionDetail, err := AzureDevopsClient.GetReleaseDefinition(project.Id, releaseDefinition.Id)
+			if err != nil {
+				logWarn(logger, err)
+				continue
+			}
+
+			for _, environment := range releaseDefinitionDetail.Environments {
+				for approvalType, approvals := range map[string]devopsClient.ReleaseDefinitionApprovals{
+					"pre":  environment.PreDeployApprovals,
+					"post": environment.PostDeployApprovals,
+				} {
+					approvalLabels := prometheus.Labels{
+						"projectID":           project.Id,
+						"releaseDefinitionID": int64ToString(releaseDefinition.Id),
+						"environmentName":     environment.Name,
+						"type":                approvalType,
+					}
+
+					releaseDefinitionEnvironmentApprovalRequiredMetric.AddBool(approvalLabels, approvals.IsRequired())
+					releaseDefinitionEnvironmentApproverCountMetric.Add(approvalLabels, float64(approvals.ApproverCount()))
+				}
+			}
+		}
 	}
 
 	// --------------------------------------
@@ -216,16 +338,26 @@ func (m *MetricsCollectorRelease) Collect(ctx context.Context, logger *log.Entry
 
 	releaseList, err := AzureDevopsClient.ListReleaseHistory(project.Id, minTime)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
+	latestReleaseList := map[int64]devopsClient.Release{}
+
 	for _, release := range releaseList.List {
+		if opts.AzureDevops.ReleaseDefinitionPathFilter != "" && !releaseDefinitionList[release.Definition.Id] {
+			continue
+		}
+
+		if latestRelease, exists := latestReleaseList[release.Definition.Id]; !exists || release.CreatedOn.After(latestRelease.CreatedOn) {
+			latestReleaseList[release.Definition.Id] = release
+		}
+
 		releaseMetric.AddInfo(prometheus.Labels{
 			"projectID":           project.Id,
 			"releaseID":           int64ToString(release.Id),
 			"releaseDefinitionID": int64ToString(release.Definition.Id),
-			"requestedBy":         release.RequestedBy.DisplayName,
+			"requestedBy":         labelLimiter.Value("requestedBy", identityLabel(release.RequestedBy)),
 			"releaseName":         release.Name,
 			"status":              release.Status,
 			"reason":              release.Reason,
@@ -300,8 +432,8 @@ func (m *MetricsCollectorRelease) Collect(ctx context.Context, logger *log.Entry
 					"trialNumber":         int64ToString(approval.TrialNumber),
 					"attempt":             int64ToString(approval.Attempt),
 					"rank":                int64ToString(approval.Rank),
-					"approver":            approval.Approver.DisplayName,
-					"approvedBy":          approval.ApprovedBy.DisplayName,
+					"approver":            identityLabel(approval.Approver),
+					"approvedBy":          identityLabel(approval.ApprovedBy),
 				}, approval.CreatedOn)
 			}
 
@@ -322,21 +454,91 @@ func (m *MetricsCollectorRelease) Collect(ctx context.Context, logger *log.Entry
 					"trialNumber":         int64ToString(approval.TrialNumber),
 					"attempt":             int64ToString(approval.Attempt),
 					"rank":                int64ToString(approval.Rank),
-					"approver":            approval.Approver.DisplayName,
-					"approvedBy":          approval.ApprovedBy.DisplayName,
+					"approver":            identityLabel(approval.Approver),
+					"approvedBy":          identityLabel(approval.ApprovedBy),
+				}, approval.CreatedOn)
+			}
+		}
+	}
+
+	for _, release := range latestReleaseList {
+		for _, environment := range release.Environments {
+			environmentPendingPromotionMetric.AddBool(prometheus.Labels{
+				"projectID":           project.Id,
+				"releaseDefinitionID": int64ToString(release.Definition.Id),
+				"environmentName":     environment.Name,
+			}, environment.Status == "notStarted")
+		}
+	}
+
+	if opts.Release.ArtifactAge {
+		for _, release := range latestReleaseList {
+			for _, artifact := range release.Artifacts {
+				if !strings.EqualFold(artifact.Type, "build") {
+					continue
+				}
+
+				buildProjectId := artifact.DefinitionReference.Project.Id
+				if buildProjectId == "" {
+					buildProjectId = project.Id
+				}
+
+				build, err := AzureDevopsClient.GetBuild(buildProjectId, artifact.DefinitionReference.Version.Id)
+				if err != nil {
+					logWarn(logger, err)
+					continue
+				}
+
+				if build.FinishTime.IsZero() || release.CreatedOn.IsZero() {
+					continue
+				}
+
+				releaseArtifactAgeMetric.AddDuration(prometheus.Labels{
+					"projectID":           project.Id,
+					"releaseDefinitionID": int64ToString(release.Definition.Id),
+					"artifactSourceId":    artifact.SourceId,
+				}, release.CreatedOn.Sub(build.FinishTime))
+			}
+		}
+	}
+
+	if opts.Release.PendingApprovers {
+		approvalList, err := AzureDevopsClient.ListPendingReleaseApprovals(project.Id)
+		if err != nil {
+			logWarn(logger, err)
+		} else {
+			for _, approval := range approvalList.List {
+				if opts.AzureDevops.ReleaseDefinitionPathFilter != "" && !releaseDefinitionList[approval.ReleaseDefinition.Id] {
+					continue
+				}
+
+				deploymentPendingApproverMetric.AddTime(prometheus.Labels{
+					"projectID":           project.Id,
+					"releaseID":           int64ToString(approval.Release.Id),
+					"releaseDefinitionID": int64ToString(approval.ReleaseDefinition.Id),
+					"environmentName":     approval.ReleaseEnvironment.Name,
+					"approvalType":        approval.ApprovalType,
+					"approverId":          identityIdLabel(approval.Approver),
+					"approverName":        identityLabel(approval.Approver),
 				}, approval.CreatedOn)
 			}
 		}
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(releaseDefinitionMetric, releaseDefinitionEnvironmentMetric, releaseMetric, releaseArtifactMetric, releaseArtifactAgeMetric, releaseEnvironmentMetric, releaseEnvironmentApprovalMetric, releaseEnvironmentStatusMetric, environmentPendingPromotionMetric, deploymentPendingApproverMetric, releaseDefinitionEnvironmentApprovalRequiredMetric, releaseDefinitionEnvironmentApproverCountMetric)
 		releaseDefinitionMetric.GaugeSet(m.prometheus.releaseDefinition)
 		releaseDefinitionEnvironmentMetric.GaugeSet(m.prometheus.releaseDefinitionEnvironment)
 
 		releaseMetric.GaugeSet(m.prometheus.release)
 		releaseArtifactMetric.GaugeSet(m.prometheus.releaseArtifact)
+		releaseArtifactAgeMetric.GaugeSet(m.prometheus.releaseArtifactAge)
 		releaseEnvironmentMetric.GaugeSet(m.prometheus.releaseEnvironment)
 		releaseEnvironmentApprovalMetric.GaugeSet(m.prometheus.releaseEnvironmentApproval)
 		releaseEnvironmentStatusMetric.GaugeSet(m.prometheus.releaseEnvironmentStatus)
+		environmentPendingPromotionMetric.GaugeSet(m.prometheus.environmentPendingPromotion)
+		deploymentPendingApproverMetric.GaugeSet(m.prometheus.deploymentPendingApprover)
+		releaseDefinitionEnvironmentApprovalRequiredMetric.GaugeSet(m.prometheus.releaseDefinitionEnvironmentApprovalRequired)
+		releaseDefinitionEnvironmentApproverCountMetric.GaugeSet(m.prometheus.releaseDefinitionEnvironmentApproverCount)
 	}
 }
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -19,6 +21,11 @@ type MetricsCollectorRepository struct {
 		repositoryStats   *prometheus.GaugeVec
 		repositoryCommits *prometheus.CounterVec
 		repositoryPushes  *prometheus.CounterVec
+
+		repositoryContributorCount *prometheus.GaugeVec
+		repositoryLastPush         *prometheus.GaugeVec
+		repositoryCommitStatus     *prometheus.GaugeVec
+		repositoryPolicy           *prometheus.GaugeVec
 	}
 }
 
@@ -74,16 +81,85 @@ func (m *MetricsCollectorRepository) Setup(collector *CollectorProject) {
 		},
 	)
 	prometheus.MustRegister(m.prometheus.repositoryPushes)
+
+	m.prometheus.repositoryContributorCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_repository_contributor_count",
+			Help: "Azure DevOps repository distinct contributors",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.repositoryContributorCount)
+
+	m.prometheus.repositoryLastPush = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_repository_last_push_timestamp_seconds",
+			Help: "Azure DevOps repository last push timestamp",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.repositoryLastPush)
+
+	m.prometheus.repositoryCommitStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_commit_status",
+			Help: "Azure DevOps number of latest commits on default branch per commit status context and state (latest status per commit)",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+			"context",
+			"state",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.repositoryCommitStatus)
+
+	m.prometheus.repositoryPolicy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_repository_policy_enabled",
+			Help: "Azure DevOps policy type enabled for repository (repository.policies.type)",
+		},
+		[]string{
+			"projectID",
+			"repositoryID",
+			"repositoryName",
+			"policyType",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.repositoryPolicy)
 }
 
 func (m *MetricsCollectorRepository) Reset() {
 	m.prometheus.repository.Reset()
 	m.prometheus.repositoryStats.Reset()
+	m.prometheus.repositoryContributorCount.Reset()
+	m.prometheus.repositoryLastPush.Reset()
+	m.prometheus.repositoryCommitStatus.Reset()
+	m.prometheus.repositoryPolicy.Reset()
 }
 
 func (m *MetricsCollectorRepository) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
 	wg := sync.WaitGroup{}
 
+	// policies are configured per project and scoped to repositories
+	var policyList []devopsClient.PolicyConfiguration
+	if opts.Repository.Policies {
+		if list, err := AzureDevopsClient.ListPolicyConfigurations(project.Id); err == nil {
+			policyList = list.List
+		} else {
+			logError(ctx, logger, err)
+		}
+	}
+
 	for _, repository := range project.RepositoryList.List {
 		if repository.Disabled() {
 			continue
@@ -93,20 +169,24 @@ func (m *MetricsCollectorRepository) Collect(ctx context.Context, logger *log.En
 		go func(ctx context.Context, callback chan<- func(), project devopsClient.Project, repository devopsClient.Repository) {
 			defer wg.Done()
 			contextLogger := logger.WithField("repository", repository.Name)
-			m.collectRepository(ctx, contextLogger, callback, project, repository)
+			m.collectRepository(ctx, contextLogger, callback, project, repository, policyList)
 		}(ctx, callback, project, repository)
 	}
 
 	wg.Wait()
 }
 
-func (m *MetricsCollectorRepository) collectRepository(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project, repository devopsClient.Repository) {
+func (m *MetricsCollectorRepository) collectRepository(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project, repository devopsClient.Repository, policyList []devopsClient.PolicyConfiguration) {
 	fromTime := *m.CollectorReference.collectionLastTime
 
 	repositoryMetric := prometheusCommon.NewMetricsList()
 	repositoryStatsMetric := prometheusCommon.NewMetricsList()
 	repositoryCommitsMetric := prometheusCommon.NewMetricsList()
 	repositoryPushesMetric := prometheusCommon.NewMetricsList()
+	repositoryContributorCountMetric := prometheusCommon.NewMetricsList()
+	repositoryLastPushMetric := prometheusCommon.NewMetricsList()
+	repositoryCommitStatusMetric := prometheusCommon.NewHashedMetricsList()
+	repositoryPolicyMetric := prometheusCommon.NewMetricsList()
 
 	repositoryMetric.AddInfo(prometheus.Labels{
 		"projectID":      project.Id,
@@ -123,14 +203,16 @@ func (m *MetricsCollectorRepository) collectRepository(ctx context.Context, logg
 	}
 
 	// get commit delta list
-	commitList, err := AzureDevopsClient.ListCommits(project.Id, repository.Id, fromTime)
+	commitList, err := AzureDevopsClient.ListCommits(project.Id, repository.Id, devopsClient.RepositoryCommitSearchCriteria{
+		FromDate: &fromTime,
+	})
 	if err == nil {
 		repositoryCommitsMetric.Add(prometheus.Labels{
 			"projectID":    project.Id,
 			"repositoryID": repository.Id,
 		}, float64(commitList.Count))
 	} else {
-		logger.Error(err)
+		logError(ctx, logger, err)
 	}
 
 	// get pushes delta list
@@ -141,13 +223,103 @@ func (m *MetricsCollectorRepository) collectRepository(ctx context.Context, logg
 			"repositoryID": repository.Id,
 		}, float64(pushList.Count))
 	} else {
-		logger.Error(err)
+		logError(ctx, logger, err)
+	}
+
+	// get distinct contributors
+	if opts.Repository.Contributors {
+		contributorFromTime := time.Now().Add(-opts.Repository.ContributorsDuration)
+		commitHistory, err := AzureDevopsClient.ListCommits(project.Id, repository.Id, devopsClient.RepositoryCommitSearchCriteria{
+			FromDate: &contributorFromTime,
+			Top:      opts.Limit.CommitsPerRepository,
+		})
+		if err == nil {
+			contributorList := map[string]bool{}
+			for _, commit := range commitHistory.List {
+				if identity, ok := authorIdentity(commit.Author); ok {
+					contributorList[identity] = true
+				}
+			}
+
+			repositoryContributorCountMetric.Add(prometheus.Labels{
+				"projectID":      project.Id,
+				"repositoryID":   repository.Id,
+				"repositoryName": repository.Name,
+			}, float64(len(contributorList)))
+		} else {
+			logError(ctx, logger, err)
+		}
+	}
+
+	// get latest push
+	if opts.Repository.LastPush {
+		latestPushList, err := AzureDevopsClient.ListLatestPushes(project.Id, repository.Id)
+		if err == nil {
+			for _, push := range latestPushList.List {
+				repositoryLastPushMetric.AddTime(prometheus.Labels{
+					"projectID":      project.Id,
+					"repositoryID":   repository.Id,
+					"repositoryName": repository.Name,
+				}, push.Date)
+			}
+		} else {
+			logError(ctx, logger, err)
+		}
+	}
+
+	// get commit statuses of default branch
+	if opts.Repository.CommitStatuses && repository.DefaultBranch != "" {
+		commitList, err := AzureDevopsClient.ListCommits(project.Id, repository.Id, devopsClient.RepositoryCommitSearchCriteria{
+			Branch:          repository.DefaultBranch,
+			Top:             opts.Repository.CommitStatusesCommits,
+			IncludeStatuses: true,
+		})
+		if err == nil {
+			for _, commit := range commitList.List {
+				for contextName, status := range commit.LatestStatuses() {
+					repositoryCommitStatusMetric.Inc(prometheus.Labels{
+						"projectID":      project.Id,
+						"repositoryID":   repository.Id,
+						"repositoryName": repository.Name,
+						"context":        contextName,
+						"state":          status.State,
+					})
+				}
+			}
+		} else {
+			logError(ctx, logger, err)
+		}
+	}
+
+	// get enabled policy types
+	if policyList != nil {
+		for _, policyType := range opts.Repository.PolicyTypes {
+			enabled := false
+			for _, policy := range policyList {
+				if strings.EqualFold(policy.Type.DisplayName, policyType) && policy.IsEffective() && policy.AppliesToRepository(repository.Id) {
+					enabled = true
+					break
+				}
+			}
+
+			repositoryPolicyMetric.AddBool(prometheus.Labels{
+				"projectID":      project.Id,
+				"repositoryID":   repository.Id,
+				"repositoryName": repository.Name,
+				"policyType":     policyType,
+			}, enabled)
+		}
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(repositoryMetric, repositoryStatsMetric, repositoryContributorCountMetric, repositoryLastPushMetric, repositoryCommitStatusMetric, repositoryPolicyMetric)
 		repositoryMetric.GaugeSet(m.prometheus.repository)
 		repositoryStatsMetric.GaugeSet(m.prometheus.repositoryStats)
 		repositoryCommitsMetric.CounterAdd(m.prometheus.repositoryCommits)
 		repositoryPushesMetric.CounterAdd(m.prometheus.repositoryPushes)
+		repositoryContributorCountMetric.GaugeSet(m.prometheus.repositoryContributorCount)
+		repositoryLastPushMetric.GaugeSet(m.prometheus.repositoryLastPush)
+		repositoryCommitStatusMetric.GaugeSet(m.prometheus.repositoryCommitStatus)
+		repositoryPolicyMetric.GaugeSet(m.prometheus.repositoryPolicy)
 	}
 }
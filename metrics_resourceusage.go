@@ -56,7 +56,7 @@ func (m *MetricsCollectorResourceUsage) Collect(ctx context.Context, logger *log
 func (m *MetricsCollectorResourceUsage) CollectResourceUsageAgent(ctx context.Context, logger *log.Entry, callback chan<- func()) {
 	resourceUsage, err := AzureDevopsClient.GetResourceUsageAgent()
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
@@ -113,6 +113,7 @@ func (m *MetricsCollectorResourceUsage) CollectResourceUsageAgent(ctx context.Co
 	}, licenseDetails.TotalHostedLicenseCount)
 
 	callback <- func() {
+		m.CollectorReference.countSeries(resourceUsageMetric)
 		resourceUsageMetric.GaugeSet(m.prometheus.resourceUsageLicense)
 	}
 }
@@ -120,7 +121,7 @@ func (m *MetricsCollectorResourceUsage) CollectResourceUsageAgent(ctx context.Co
 func (m *MetricsCollectorResourceUsage) CollectResourceUsageBuild(ctx context.Context, logger *log.Entry, callback chan<- func()) {
 	resourceUsage, err := AzureDevopsClient.GetResourceUsageBuild()
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
@@ -151,6 +152,7 @@ func (m *MetricsCollectorResourceUsage) CollectResourceUsageBuild(ctx context.Co
 	}
 
 	callback <- func() {
+		m.CollectorReference.countSeries(resourceUsageMetric)
 		resourceUsageMetric.GaugeSet(m.prometheus.resourceUsageBuild)
 	}
 
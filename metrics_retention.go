@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+type MetricsCollectorRetention struct {
+	CollectorProcessorProject
+
+	prometheus struct {
+		retentionLeaseCount *prometheus.GaugeVec
+		retentionSetting    *prometheus.GaugeVec
+		retentionCompliant  *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorRetention) Setup(collector *CollectorProject) {
+	m.CollectorReference = collector
+
+	m.prometheus.retentionLeaseCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_project_retention_lease_count",
+			Help: "Azure DevOps number of retention leases per project",
+		},
+		[]string{
+			"projectID",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.retentionLeaseCount)
+
+	m.prometheus.retentionSetting = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_project_retention_setting",
+			Help: "Azure DevOps pipeline retention settings per project (days or runs)",
+		},
+		[]string{
+			"projectID",
+			"type",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.retentionSetting)
+
+	m.prometheus.retentionCompliant = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_project_retention_compliant",
+			Help: "Azure DevOps pipeline run retention meets project.retention.min-days",
+		},
+		[]string{
+			"projectID",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.retentionCompliant)
+}
+
+func (m *MetricsCollectorRetention) Reset() {
+	m.prometheus.retentionLeaseCount.Reset()
+	m.prometheus.retentionSetting.Reset()
+	m.prometheus.retentionCompliant.Reset()
+}
+
+func (m *MetricsCollectorRetention) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	m.collectRetentionLeases(ctx, logger, callback, project)
+	m.collectRetentionSettings(ctx, logger, callback, project)
+}
+
+func (m *MetricsCollectorRetention) collectRetentionLeases(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	// leases api requires a filter, leases are fetched per build definition
+	definitionList, err := AzureDevopsClient.ListBuildDefinitions(project.Id, false)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	leaseCount := 0
+	for _, definition := range definitionList.List {
+		list, err := AzureDevopsClient.ListRetentionLeases(project.Id, definition.Id)
+		if err != nil {
+			logError(ctx, logger, err)
+			return
+		}
+
+		leaseCount += len(list.List)
+	}
+
+	retentionLeaseCountMetric := prometheusCommon.NewMetricsList()
+
+	retentionLeaseCountMetric.Add(prometheus.Labels{
+		"projectID": project.Id,
+	}, float64(leaseCount))
+
+	callback <- func() {
+		m.CollectorReference.countSeries(retentionLeaseCountMetric)
+		retentionLeaseCountMetric.GaugeSet(m.prometheus.retentionLeaseCount)
+	}
+}
+
+func (m *MetricsCollectorRetention) collectRetentionSettings(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	setting, err := AzureDevopsClient.GetProjectRetentionSetting(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	retentionSettingMetric := prometheusCommon.NewMetricsList()
+	retentionCompliantMetric := prometheusCommon.NewMetricsList()
+
+	retentionSettingMetric.Add(prometheus.Labels{
+		"projectID": project.Id,
+		"type":      "runs",
+	}, float64(setting.PurgeRuns.Value))
+
+	retentionSettingMetric.Add(prometheus.Labels{
+		"projectID": project.Id,
+		"type":      "artifacts",
+	}, float64(setting.PurgeArtifacts.Value))
+
+	retentionSettingMetric.Add(prometheus.Labels{
+		"projectID": project.Id,
+		"type":      "pullRequestRuns",
+	}, float64(setting.PurgePullRequestRuns.Value))
+
+	if setting.RetainRunsPerProtectedBranch != nil {
+		retentionSettingMetric.Add(prometheus.Labels{
+			"projectID": project.Id,
+			"type":      "runsPerProtectedBranch",
+		}, float64(setting.RetainRunsPerProtectedBranch.Value))
+	}
+
+	retentionCompliantMetric.AddBool(prometheus.Labels{
+		"projectID": project.Id,
+	}, setting.PurgeRuns.Value >= opts.Project.RetentionMinDays)
+
+	callback <- func() {
+		m.CollectorReference.countSeries(retentionSettingMetric, retentionCompliantMetric)
+		retentionSettingMetric.GaugeSet(m.prometheus.retentionSetting)
+		retentionCompliantMetric.GaugeSet(m.prometheus.retentionCompliant)
+	}
+}
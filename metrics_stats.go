@@ -175,7 +175,7 @@ func (m *MetricsCollectorStats) CollectReleases(ctx context.Context, logger *log
 
 	releaseList, err := AzureDevopsClient.ListReleaseHistory(project.Id, minTime)
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
@@ -214,7 +214,7 @@ func (m *MetricsCollectorStats) CollectBuilds(ctx context.Context, logger *log.E
 
 	buildList, err := AzureDevopsClient.ListBuildHistoryWithStatus(project.Id, minTime, "completed")
 	if err != nil {
-		logger.Error(err)
+		logError(ctx, logger, err)
 		return
 	}
 
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+type MetricsCollectorTeam struct {
+	CollectorProcessorProject
+
+	prometheus struct {
+		teamIteration       *prometheus.GaugeVec
+		teamIterationStatus *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorTeam) Setup(collector *CollectorProject) {
+	m.CollectorReference = collector
+
+	m.prometheus.teamIteration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_team_iteration_info",
+			Help: "Azure DevOps current iteration (sprint) of team",
+		},
+		[]string{
+			"projectID",
+			"teamId",
+			"team",
+			"iterationId",
+			"iterationName",
+			"iterationPath",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.teamIteration)
+
+	m.prometheus.teamIterationStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_team_iteration_status",
+			Help: "Azure DevOps current iteration (sprint) start and finish date of team",
+		},
+		[]string{
+			"projectID",
+			"teamId",
+			"iterationPath",
+			"type",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.teamIterationStatus)
+}
+
+func (m *MetricsCollectorTeam) Reset() {
+	m.prometheus.teamIteration.Reset()
+	m.prometheus.teamIterationStatus.Reset()
+}
+
+func (m *MetricsCollectorTeam) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	teamList, err := AzureDevopsClient.ListTeams(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	teamIterationMetric := prometheusCommon.NewMetricsList()
+	teamIterationStatusMetric := prometheusCommon.NewMetricsList()
+
+	for _, team := range teamList.List {
+		contextLogger := logger.WithField("team", team.Name)
+
+		// teams without current iteration return an empty list
+		iterationList, err := AzureDevopsClient.ListTeamCurrentIterations(project.Id, team.Id)
+		if err != nil {
+			logWarn(contextLogger, err)
+			continue
+		}
+
+		for _, iteration := range iterationList.List {
+			teamIterationMetric.AddInfo(prometheus.Labels{
+				"projectID":     project.Id,
+				"teamId":        team.Id,
+				"team":          team.Name,
+				"iterationId":   iteration.Id,
+				"iterationName": iteration.Name,
+				"iterationPath": iteration.Path,
+			})
+
+			if iteration.Attributes.StartDate != nil {
+				teamIterationStatusMetric.AddTime(prometheus.Labels{
+					"projectID":     project.Id,
+					"teamId":        team.Id,
+					"iterationPath": iteration.Path,
+					"type":          "start",
+				}, *iteration.Attributes.StartDate)
+			}
+
+			if iteration.Attributes.FinishDate != nil {
+				teamIterationStatusMetric.AddTime(prometheus.Labels{
+					"projectID":     project.Id,
+					"teamId":        team.Id,
+					"iterationPath": iteration.Path,
+					"type":          "finish",
+				}, *iteration.Attributes.FinishDate)
+			}
+		}
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(teamIterationMetric, teamIterationStatusMetric)
+		teamIterationMetric.GaugeSet(m.prometheus.teamIteration)
+		teamIterationStatusMetric.GaugeSet(m.prometheus.teamIterationStatus)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	prometheusCommon "github.com/webdevops/go-common/prometheus"
+
+	devopsClient "github.com/webdevops/azure-devops-exporter/azure-devops-client"
+)
+
+type MetricsCollectorVariableGroup struct {
+	CollectorProcessorProject
+
+	prometheus struct {
+		variableGroup            *prometheus.GaugeVec
+		variableGroupKeyVault    *prometheus.GaugeVec
+		variableGroupSecretCount *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorVariableGroup) Setup(collector *CollectorProject) {
+	m.CollectorReference = collector
+
+	m.prometheus.variableGroup = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_variablegroup_info",
+			Help: "Azure DevOps variable group",
+		},
+		[]string{
+			"projectID",
+			"variableGroupId",
+			"variableGroupName",
+			"type",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.variableGroup)
+
+	m.prometheus.variableGroupKeyVault = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_variablegroup_keyvault",
+			Help: "Azure DevOps variable group is linked to Azure KeyVault",
+		},
+		[]string{
+			"projectID",
+			"variableGroupId",
+			"variableGroupName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.variableGroupKeyVault)
+
+	m.prometheus.variableGroupSecretCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azure_devops_variablegroup_secret_count",
+			Help: "Azure DevOps number of secret variables per variable group",
+		},
+		[]string{
+			"projectID",
+			"variableGroupId",
+			"variableGroupName",
+		},
+	)
+	prometheus.MustRegister(m.prometheus.variableGroupSecretCount)
+}
+
+func (m *MetricsCollectorVariableGroup) Reset() {
+	m.prometheus.variableGroup.Reset()
+	m.prometheus.variableGroupKeyVault.Reset()
+	m.prometheus.variableGroupSecretCount.Reset()
+}
+
+func (m *MetricsCollectorVariableGroup) Collect(ctx context.Context, logger *log.Entry, callback chan<- func(), project devopsClient.Project) {
+	list, err := AzureDevopsClient.ListVariableGroups(project.Id)
+	if err != nil {
+		logError(ctx, logger, err)
+		return
+	}
+
+	variableGroupMetric := prometheusCommon.NewMetricsList()
+	variableGroupKeyVaultMetric := prometheusCommon.NewMetricsList()
+	variableGroupSecretCountMetric := prometheusCommon.NewMetricsList()
+
+	for _, variableGroup := range list.List {
+		variableGroupMetric.AddInfo(prometheus.Labels{
+			"projectID":         project.Id,
+			"variableGroupId":   int64ToString(variableGroup.Id),
+			"variableGroupName": variableGroup.Name,
+			"type":              variableGroup.Type,
+		})
+
+		groupLabels := prometheus.Labels{
+			"projectID":         project.Id,
+			"variableGroupId":   int64ToString(variableGroup.Id),
+			"variableGroupName": variableGroup.Name,
+		}
+
+		variableGroupKeyVaultMetric.AddBool(groupLabels, variableGroup.IsKeyVault())
+
+		// keyvault variables are always secret, only inline secrets are counted
+		if !variableGroup.IsKeyVault() {
+			variableGroupSecretCountMetric.Add(groupLabels, float64(variableGroup.SecretCount()))
+		}
+	}
+
+	callback <- func() {
+		m.CollectorReference.countSeries(variableGroupMetric, variableGroupKeyVaultMetric, variableGroupSecretCountMetric)
+		variableGroupMetric.GaugeSet(m.prometheus.variableGroup)
+		variableGroupKeyVaultMetric.GaugeSet(m.prometheus.variableGroupKeyVault)
+		variableGroupSecretCountMetric.GaugeSet(m.prometheus.variableGroupSecretCount)
+	}
+}
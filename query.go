@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// prefix for query folders ('folder:<path>@<projectId>')
+	queryFolderPrefix = "folder:"
+
+	// max depth supported by the queries API
+	queryFolderMaxDepth = 2
+
+	// prefix for labels of grouped work item fields (eg. 'Custom.Severity' -> 'field_Custom_Severity')
+	queryGroupByLabelPrefix = "field_"
+)
+
+var (
+	// work item field reference names (eg. 'System.State', 'Custom.Severity')
+	queryFieldNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*(\.[a-zA-Z0-9_]+)+$`)
+)
+
+type (
+	// querySpec is a parsed query definition in the form '<queryId>[;option=value]@<projectId>[;option=value]'
+	// or 'folder:<folderPath>[;option=value]@<projectId>[;option=value]'
+	querySpec struct {
+		Raw       string
+		QueryPath string
+		ProjectID string
+
+		// query folder (all queries inside the folder up to FolderDepth)
+		Folder      bool
+		FolderDepth int
+
+		// filter work items by area path (including child areas)
+		AreaPath string
+
+		// count work items per area path
+		AreaPathCount bool
+
+		// scrape interval (overrides scrape.time.query)
+		Interval *time.Duration
+
+		// follow child links and roll up child states (additional requests per work item)
+		Children bool
+
+		// group query count by work item fields (reference names)
+		GroupBy []string
+
+		// count work items per state and work item type (backlog health)
+		Backlog bool
+
+		// queries resolved from the folder, used to reset metrics of (removed) queries
+		folderQueryLock  sync.Mutex
+		folderQueryPaths map[string]bool
+	}
+)
+
+func parseQuerySpec(val string) (*querySpec, error) {
+	if strings.Count(val, "@") != 1 {
+		return nil, fmt.Errorf("query '%v' is malformed; should be '<query UUID>@<project UUID>'", val)
+	}
+
+	spec := &querySpec{Raw: val}
+
+	parts := strings.SplitN(val, "@", 2)
+	querySegments := strings.Split(parts[0], ";")
+	projectSegments := strings.Split(parts[1], ";")
+
+	spec.QueryPath = strings.TrimSpace(querySegments[0])
+	spec.ProjectID = strings.TrimSpace(projectSegments[0])
+
+	if strings.HasPrefix(strings.ToLower(spec.QueryPath), queryFolderPrefix) {
+		spec.Folder = true
+		spec.FolderDepth = 1
+		spec.QueryPath = strings.TrimSpace(spec.QueryPath[len(queryFolderPrefix):])
+	}
+
+	if spec.QueryPath == "" || spec.ProjectID == "" {
+		return nil, fmt.Errorf("query '%v' is malformed; should be '<query UUID>@<project UUID>'", val)
+	}
+
+	optionList := append(querySegments[1:], projectSegments[1:]...)
+	for _, option := range optionList {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+
+		optionParts := strings.SplitN(option, "=", 2)
+		if len(optionParts) != 2 {
+			return nil, fmt.Errorf("query '%v' has malformed option '%v'; should be 'option=value'", val, option)
+		}
+
+		optionName := strings.ToLower(strings.TrimSpace(optionParts[0]))
+		optionValue := strings.TrimSpace(optionParts[1])
+
+		switch optionName {
+		case "areapath":
+			spec.AreaPath = optionValue
+		case "areapathcount":
+			areaPathCount, err := strconv.ParseBool(optionValue)
+			if err != nil {
+				return nil, fmt.Errorf("query '%v' has invalid areaPathCount value '%v'", val, optionValue)
+			}
+			spec.AreaPathCount = areaPathCount
+		case "interval":
+			interval, err := time.ParseDuration(optionValue)
+			if err != nil || interval.Seconds() <= 0 {
+				return nil, fmt.Errorf("query '%v' has invalid interval '%v'", val, optionValue)
+			}
+			spec.Interval = &interval
+		case "children":
+			children, err := strconv.ParseBool(optionValue)
+			if err != nil {
+				return nil, fmt.Errorf("query '%v' has invalid children value '%v'", val, optionValue)
+			}
+			spec.Children = children
+		case "backlog":
+			backlog, err := strconv.ParseBool(optionValue)
+			if err != nil {
+				return nil, fmt.Errorf("query '%v' has invalid backlog value '%v'", val, optionValue)
+			}
+			spec.Backlog = backlog
+		case "depth":
+			depth, err := strconv.Atoi(optionValue)
+			if err != nil || depth < 1 || depth > queryFolderMaxDepth {
+				return nil, fmt.Errorf("query '%v' has invalid depth '%v'; must be between 1 and %v", val, optionValue, queryFolderMaxDepth)
+			}
+			spec.FolderDepth = depth
+		case "groupby":
+			for _, fieldName := range strings.Split(optionValue, ",") {
+				fieldName = strings.TrimSpace(fieldName)
+				if !queryFieldNameRegexp.MatchString(fieldName) {
+					return nil, fmt.Errorf("query '%v' has invalid groupBy field '%v'; should be a field reference name (eg. 'Custom.Severity')", val, fieldName)
+				}
+				spec.GroupBy = append(spec.GroupBy, fieldName)
+			}
+		default:
+			return nil, fmt.Errorf("query '%v' has unknown option '%v'", val, optionParts[0])
+		}
+	}
+
+	if spec.FolderDepth != 0 && !spec.Folder {
+		return nil, fmt.Errorf("query '%v' has option depth but is not a query folder", val)
+	}
+
+	return spec, nil
+}
+
+// folderQuery returns the spec for a query inside the query folder (inherits all options)
+func (q *querySpec) folderQuery(queryId string) *querySpec {
+	q.folderQueryLock.Lock()
+	defer q.folderQueryLock.Unlock()
+
+	if q.folderQueryPaths == nil {
+		q.folderQueryPaths = map[string]bool{}
+	}
+	q.folderQueryPaths[queryId] = true
+
+	return &querySpec{
+		Raw:           q.Raw,
+		QueryPath:     queryId,
+		ProjectID:     q.ProjectID,
+		AreaPath:      q.AreaPath,
+		AreaPathCount: q.AreaPathCount,
+		Interval:      q.Interval,
+		Children:      q.Children,
+		GroupBy:       q.GroupBy,
+		Backlog:       q.Backlog,
+	}
+}
+
+// queryGroupByLabel returns the metric label name for a grouped work item field
+func queryGroupByLabel(fieldName string) string {
+	return queryGroupByLabelPrefix + strings.ReplaceAll(fieldName, ".", "_")
+}
+
+// QueryPathList returns the query paths of the metrics (all known queries for query folders)
+func (q *querySpec) QueryPathList() (list []string) {
+	if !q.Folder {
+		return []string{q.QueryPath}
+	}
+
+	q.folderQueryLock.Lock()
+	defer q.folderQueryLock.Unlock()
+
+	for queryPath := range q.folderQueryPaths {
+		list = append(list, queryPath)
+	}
+
+	return
+}
+
+// MatchAreaPath checks if the area path is the configured area path or a child of it
+func (q *querySpec) MatchAreaPath(areaPath string) bool {
+	if q.AreaPath == "" {
+		return true
+	}
+
+	if strings.EqualFold(areaPath, q.AreaPath) {
+		return true
+	}
+
+	return strings.HasPrefix(strings.ToLower(areaPath), strings.ToLower(strings.TrimSuffix(q.AreaPath, `\`)+`\`))
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"hash/fnv"
+	"strings"
 	"sync"
 	"time"
 
@@ -91,6 +93,19 @@ func (sd *azureDevopsServiceDiscovery) ProjectList() (list []AzureDevops.Project
 		}
 	}
 
+	// sharding
+	if opts.Sharding.ShardTotal > 1 {
+		rawList := list
+		list = []AzureDevops.Project{}
+		for _, project := range rawList {
+			if projectShardIndex(project.Id, opts.Sharding.ShardTotal) == opts.Sharding.ShardIndex {
+				list = append(list, project)
+			}
+		}
+
+		sd.logger.Infof("using %v projects for shard %v/%v", len(list), opts.Sharding.ShardIndex, opts.Sharding.ShardTotal)
+	}
+
 	// save to cache
 	sd.cache.SetDefault(azureDevopsServiceDiscoveryCacheKeyProjectList, list)
 
@@ -130,3 +145,10 @@ func (sd *azureDevopsServiceDiscovery) AgentPoolList() (list []int64) {
 
 	return
 }
+
+// projectShardIndex returns the (deterministic) shard of the project
+func projectShardIndex(projectId string, shardTotal int) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(strings.ToLower(projectId)))
+	return int(hash.Sum32() % uint32(shardTotal))
+}